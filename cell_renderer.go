@@ -0,0 +1,322 @@
+package tea
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea/vt"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// cell is a single cell of a rendered frame.
+type cell struct {
+	// content is the grapheme cluster drawn in the cell. It's empty for the
+	// cells covered by the right-hand side of a wide character.
+	content string
+	width   int
+	style   vt.Style
+	link    string
+}
+
+// isContinuation reports whether the cell is covered by a wide character to
+// its left.
+func (c cell) isContinuation() bool {
+	return c.width == 0
+}
+
+// styleSequence returns the SGR sequence that sets the style from a reset
+// state.
+func styleSequence(s vt.Style) string {
+	var params []string
+	for _, attr := range []struct {
+		set   bool
+		param string
+	}{
+		{s.Bold, "1"},
+		{s.Faint, "2"},
+		{s.Italic, "3"},
+		{s.Blink, "5"},
+		{s.Reverse, "7"},
+		{s.Conceal, "8"},
+		{s.Strikethrough, "9"},
+		{s.Overline, "53"},
+	} {
+		if attr.set {
+			params = append(params, attr.param)
+		}
+	}
+	switch s.Underline {
+	case ansi.NoUnderlineStyle:
+	case ansi.SingleUnderlineStyle:
+		params = append(params, "4")
+	default:
+		params = append(params, "4:"+strconv.Itoa(int(s.Underline)))
+	}
+	for _, c := range []struct {
+		color ansi.Color
+		base  int
+	}{
+		{s.Fg, 30},             //nolint:mnd
+		{s.Bg, 40},             //nolint:mnd
+		{s.UnderlineColor, 50}, //nolint:mnd
+	} {
+		if c.color != nil {
+			params = append(params, colorParams(c.color, c.base))
+		}
+	}
+	return "\x1b[0;" + strings.Join(params, ";") + "m"
+}
+
+// colorParams returns the SGR parameters that set a color, given the base of
+// the basic colors: 30 for the foreground, 40 for the background and 50 for
+// the underline, which has no basic colors.
+func colorParams(c ansi.Color, base int) string {
+	switch c := c.(type) {
+	case ansi.BasicColor:
+		switch {
+		case base == 50: //nolint:mnd
+			return "58;5;" + strconv.Itoa(int(c))
+		case c < 8: //nolint:mnd
+			return strconv.Itoa(base + int(c))
+		default:
+			return strconv.Itoa(base + 60 + int(c) - 8) //nolint:mnd
+		}
+	case ansi.ExtendedColor:
+		return strconv.Itoa(base+8) + ";5;" + strconv.Itoa(int(c)) //nolint:mnd
+	}
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("%d;2;%d;%d;%d", base+8, r>>8, g>>8, b>>8) //nolint:mnd
+}
+
+// sgrParams returns the parameters of an SGR sequence.
+func sgrParams(seq string) []string {
+	params := seq[len("\x1b[") : len(seq)-1]
+	if params == "" {
+		return nil
+	}
+	return strings.Split(params, ";")
+}
+
+// cellParser breaks rendered lines into cells. Like a terminal, it keeps the
+// style and hyperlink from the end of one line for the next one.
+type cellParser struct {
+	pen
+}
+
+// parse breaks a line into cells, dropping cells beyond the given width when
+// it's known. Escape sequences other than SGR styles and OSC 8 hyperlinks
+// don't occupy cells and are dropped.
+func (p *cellParser) parse(line string, width int) []cell {
+	var cells []cell
+	var state byte
+	truncated := false
+	for len(line) > 0 {
+		seq, w, n, newState := ansi.DecodeSequence(line, state, nil)
+		state = newState
+		line = line[n:]
+
+		switch {
+		case w > 0:
+			if truncated || width > 0 && len(cells)+w > width {
+				// Keep reading the styles of the truncated cells, as they
+				// carry over to the next line.
+				truncated = true
+				continue
+			}
+			cells = append(cells, cell{content: seq, width: w, style: p.style, link: p.link})
+			for i := 1; i < w; i++ {
+				cells = append(cells, cell{style: p.style, link: p.link})
+			}
+		case strings.HasPrefix(seq, "\x1b[") && strings.HasSuffix(seq, "m"):
+			p.style.Apply(sgrParams(seq))
+		case strings.HasPrefix(seq, "\x1b]8;"):
+			p.link = strings.TrimSuffix(strings.TrimSuffix(seq[len("\x1b]8;"):], "\x07"), "\x1b\\")
+			if strings.HasSuffix(p.link, ";") {
+				// An empty URI closes the hyperlink.
+				p.link = ""
+			}
+		}
+	}
+	return cells
+}
+
+// parseCells breaks the lines of a frame into cells.
+func parseCells(lines []string, width int) [][]cell {
+	var p cellParser
+	cells := make([][]cell, len(lines))
+	for i, line := range lines {
+		cells[i] = p.parse(line, width)
+	}
+	return cells
+}
+
+// pen is the style and hyperlink the terminal draws characters with.
+type pen struct {
+	style vt.Style
+	link  string
+}
+
+// set writes the sequences that change the pen to the given style and
+// hyperlink.
+func (p *pen) set(buf *bytes.Buffer, style vt.Style, link string) {
+	if style != p.style {
+		if style.IsZero() {
+			buf.WriteString(ansi.ResetStyle)
+		} else {
+			buf.WriteString(styleSequence(style))
+		}
+		p.style = style
+	}
+	if link != p.link {
+		if link == "" {
+			buf.WriteString(ansi.ResetHyperlink())
+		} else {
+			buf.WriteString("\x1b]8;" + link + "\x07")
+		}
+		p.link = link
+	}
+}
+
+// cellWriter writes the difference between two frames of cells, keeping
+// track of the cursor and pen so it only emits the sequences it needs.
+type cellWriter struct {
+	buf *bytes.Buffer
+
+	// cursor position, relative to the top of the rendered area
+	x, y int
+
+	// rows is the number of rows the terminal has rendered so far; moving
+	// the cursor below them needs a line feed so the terminal can scroll.
+	rows int
+
+	// width is the terminal width, or zero if unknown.
+	width int
+
+	pen pen
+}
+
+// moveTo moves the cursor to the given cell.
+func (w *cellWriter) moveTo(x, y int) {
+	if y > w.y {
+		n := y - w.y
+		if y < w.rows && n > 3 { //nolint:mnd
+			w.buf.WriteString(ansi.CursorDown(n))
+		} else {
+			w.buf.WriteString(strings.Repeat("\n", n))
+		}
+		if y >= w.rows {
+			w.rows = y + 1
+		}
+	} else if y < w.y {
+		w.buf.WriteString(ansi.CursorUp(w.y - y))
+	}
+	w.y = y
+
+	switch {
+	case x == w.x:
+	case x == 0:
+		w.buf.WriteByte('\r')
+	case x > w.x:
+		w.buf.WriteString(ansi.CursorForward(x - w.x))
+	default:
+		w.buf.WriteString(ansi.CursorBackward(w.x - x))
+	}
+	w.x = x
+}
+
+// writeCell draws a cell at the cursor position.
+func (w *cellWriter) writeCell(c cell) {
+	w.pen.set(w.buf, c.style, c.link)
+	w.buf.WriteString(c.content)
+	w.x += c.width
+	if w.width > 0 && w.x >= w.width {
+		// The cursor stays on the last column until the next character is
+		// written.
+		w.x = w.width - 1
+	}
+}
+
+// resetPen resets the style and hyperlink, e.g. before erasing so erased
+// cells get the default background.
+func (w *cellWriter) resetPen() {
+	w.pen.set(w.buf, vt.Style{}, "")
+}
+
+// diffRow writes the cells of a row that changed since the previous frame.
+func (w *cellWriter) diffRow(y int, prev, next []cell) {
+	// Unchanged gaps shorter than this are rewritten rather than skipped, as
+	// a cursor movement would take more bytes.
+	const maxGap = 3
+
+	for i := 0; i < len(next); {
+		if i < len(prev) && prev[i] == next[i] {
+			i++
+			continue
+		}
+
+		start := i
+		if next[start].isContinuation() && start > 0 {
+			start--
+		}
+		end := i + 1
+		for gap := 0; end < len(next) && gap <= maxGap; end++ {
+			if end < len(prev) && prev[end] == next[end] {
+				gap++
+				continue
+			}
+			gap = 0
+		}
+		// Trim the unchanged cells at the end of the run.
+		for end > i+1 && end <= len(prev) && prev[end-1] == next[end-1] {
+			end--
+		}
+
+		w.moveTo(start, y)
+		for _, c := range next[start:end] {
+			if !c.isContinuation() {
+				w.writeCell(c)
+			}
+		}
+		i = end
+	}
+
+	if len(prev) > len(next) {
+		w.moveTo(len(next), y)
+		w.resetPen()
+		w.buf.WriteString(ansi.EraseLineRight)
+	}
+}
+
+// flushCells writes the difference between the previously rendered frame and
+// the new one, leaving the cursor at the start of the last line. It assumes
+// the cursor is at the start of the last line of the previous frame.
+func (r *standardRenderer) flushCells(buf *bytes.Buffer, newLines []string) {
+	prev := r.lastCells
+	next := parseCells(newLines, r.width)
+
+	w := &cellWriter{
+		buf:   buf,
+		y:     len(prev) - 1,
+		rows:  len(prev),
+		width: r.width,
+	}
+	for y := range next {
+		var prevRow []cell
+		if y < len(prev) {
+			prevRow = prev[y]
+		}
+		w.diffRow(y, prevRow, next[y])
+	}
+	w.resetPen()
+
+	// Clearing left over content from last render.
+	if len(prev) > len(next) {
+		w.moveTo(0, len(next))
+		buf.WriteString(ansi.EraseScreenBelow)
+	}
+	w.moveTo(0, len(next)-1)
+
+	r.lastCells = next
+}
@@ -0,0 +1,158 @@
+package tea
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/bubbletea/vt"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestParseCells(t *testing.T) {
+	bold := vt.Style{Bold: true, Fg: ansi.ExtendedColor(208)}
+	var p cellParser
+	cells := p.parse("a\x1b[1;38;5;208m世\x1b[mb\x1b]8;;https://example.com\x07c\x1b]8;;\x07", 0)
+	expected := []cell{
+		{content: "a", width: 1},
+		{content: "世", width: 2, style: bold},
+		{style: bold},
+		{content: "b", width: 1},
+		{content: "c", width: 1, link: ";https://example.com"},
+	}
+	if !reflect.DeepEqual(cells, expected) {
+		t.Errorf("expected cells:\n%#v\ngot:\n%#v", expected, cells)
+	}
+
+	if got := p.parse("ab世c", 3); len(got) != 2 {
+		t.Errorf("expected the wide character to be truncated, got %#v", got)
+	}
+
+	// Styles carry over to the next line, even from truncated cells.
+	lines := parseCells([]string{"ab\x1b[1mc", "d"}, 2)
+	if got := lines[1][0].style; !got.Bold {
+		t.Errorf("expected the style to carry over to the next line, got %+v", got)
+	}
+
+	if got := styleSequence(bold); got != "\x1b[0;1;38;5;208m" {
+		t.Errorf("unexpected style sequence %q", got)
+	}
+
+	// Styles are written back as they were parsed.
+	for _, seq := range []string{
+		"\x1b[1;3;4:3;53;91;44m",
+		"\x1b[21;38:2::255:0:10;48;5;17;58;5;3m",
+		"\x1b[2;7;9;38;2;1;2;3m",
+	} {
+		var style vt.Style
+		style.Apply(sgrParams(seq))
+		var got vt.Style
+		got.Apply(sgrParams(styleSequence(style)))
+		if got != style {
+			t.Errorf("%q: expected %+v from %q, got %+v", seq, style, styleSequence(style), got)
+		}
+	}
+}
+
+func TestCellRendererDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		prev     string
+		next     string
+		expected string
+	}{
+		{
+			name:     "changed word",
+			prev:     "hello world\nsecond",
+			next:     "hello there\nsecond",
+			expected: "\x1b[A\x1b[6Cthere\n\r",
+		},
+		{
+			name:     "unchanged",
+			prev:     "one\ntwo",
+			next:     "one\ntwo\n",
+			expected: "\n",
+		},
+		{
+			name:     "style change",
+			prev:     "12:00:00",
+			next:     "12:00:\x1b[31m01\x1b[m",
+			expected: "\x1b[6C\x1b[0;31m01\x1b[m\r",
+		},
+		{
+			name:     "shrink",
+			prev:     "abcdef\nline",
+			next:     "abc",
+			expected: "\x1b[A\x1b[3C\x1b[K\n\r\x1b[J\x1b[A",
+		},
+		{
+			name:     "grow",
+			prev:     "a",
+			next:     "a\nb",
+			expected: "\nb\r",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			r := newRenderer(&buf, false, 60, true).(*standardRenderer)
+			r.width, r.height = 80, 24
+
+			r.Write(test.prev)
+			r.flush()
+			buf.Reset()
+
+			r.Write(test.next)
+			r.flush()
+			if buf.String() != test.expected {
+				t.Errorf("expected output:\n%q\ngot:\n%q", test.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestCellRendererStyles(t *testing.T) {
+	tests := []struct {
+		name   string
+		frames []string
+	}{
+		{
+			name:   "style left open by a full repaint",
+			frames: []string{"\x1b[31maaax", "a\x1b[0mxc"},
+		},
+		{
+			name:   "style spanning lines",
+			frames: []string{"\x1b[1mfoo\nbar\x1b[m baz", "\x1b[1mfoo\nbaz\x1b[m bar"},
+		},
+		{
+			name:   "hyperlink spanning lines",
+			frames: []string{"\x1b]8;;https://example.com\x07ab\ncd\x1b]8;;\x07 e", "\x1b]8;;https://example.com\x07ab\nce\x1b]8;;\x07 e"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			term := vt.New(20, 4)
+			r := newRenderer(term, false, 60, true).(*standardRenderer)
+			r.HandleMessage(WindowSizeMsg{Width: 20, Height: 4})
+			for _, frame := range test.frames {
+				r.Write(frame)
+				r.flush()
+			}
+
+			// The screen must match a full render of the last frame.
+			expected := vt.New(20, 4)
+			full := newRenderer(expected, false, 60, false).(*standardRenderer)
+			full.HandleMessage(WindowSizeMsg{Width: 20, Height: 4})
+			full.Write(test.frames[len(test.frames)-1])
+			full.flush()
+
+			for y := 0; y < 4; y++ {
+				if got, want := term.Line(y), expected.Line(y); !reflect.DeepEqual(got, want) {
+					t.Errorf("line %d: expected cells:\n%+v\ngot:\n%+v", y, want, got)
+				}
+			}
+		})
+	}
+}
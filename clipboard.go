@@ -0,0 +1,115 @@
+package tea
+
+import (
+	"bytes"
+	"encoding/base64"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// ClipboardMsg is sent with the content of the clipboard in reply to
+// [ReadClipboard] and [ReadPrimaryClipboard]. Not all terminals support
+// reading the clipboard, and some ask the user for permission first, so a
+// program might never get a reply.
+type ClipboardMsg struct {
+	// Content is the text in the clipboard.
+	Content string
+
+	// Primary reports whether the content is from the primary selection
+	// rather than the system clipboard.
+	Primary bool
+}
+
+// String returns the content of the clipboard.
+func (m ClipboardMsg) String() string {
+	return m.Content
+}
+
+// setClipboardMsg is an internal message used to set the clipboard.
+type setClipboardMsg struct {
+	selection byte
+	content   string
+}
+
+// readClipboardMsg is an internal message used to request the content of the
+// clipboard.
+type readClipboardMsg byte
+
+// SetClipboard produces a command that copies the given text to the system
+// clipboard using OSC 52. It works over SSH too, as long as the terminal
+// supports OSC 52.
+//
+// For example:
+//
+//	func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+//		switch msg := msg.(type) {
+//		case tea.KeyMsg:
+//			if msg.String() == "y" {
+//				return m, tea.SetClipboard(m.selected)
+//			}
+//		}
+//		return m, nil
+//	}
+func SetClipboard(text string) Cmd {
+	return func() Msg {
+		return setClipboardMsg{selection: ansi.SystemClipboard, content: text}
+	}
+}
+
+// SetPrimaryClipboard produces a command that copies the given text to the
+// primary selection using OSC 52. The primary selection is the one pasted
+// with the middle mouse button on X11.
+func SetPrimaryClipboard(text string) Cmd {
+	return func() Msg {
+		return setClipboardMsg{selection: ansi.PrimaryClipboard, content: text}
+	}
+}
+
+// ReadClipboard produces a command that asks the terminal for the content of
+// the system clipboard. The reply is sent to the program as a
+// [ClipboardMsg].
+func ReadClipboard() Cmd {
+	return func() Msg {
+		return readClipboardMsg(ansi.SystemClipboard)
+	}
+}
+
+// ReadPrimaryClipboard produces a command that asks the terminal for the
+// content of the primary selection. The reply is sent to the program as a
+// [ClipboardMsg].
+func ReadPrimaryClipboard() Cmd {
+	return func() Msg {
+		return readClipboardMsg(ansi.PrimaryClipboard)
+	}
+}
+
+// detectClipboard detects an OSC 52 reply with the content of the
+// clipboard, terminated with either BEL or ST:
+//
+//	OSC 52 ; Pc ; Pd BEL
+func detectClipboard(input []byte) (hasClip bool, width int, msg Msg) {
+	const clipStart = "\x1b]52;"
+	if !bytes.HasPrefix(input, []byte(clipStart)) {
+		return false, 0, nil
+	}
+
+	data, width, ok := cutStringTerminator(input[len(clipStart):])
+	if !ok {
+		// The reply continues beyond the input buffer. Tell the outer loop
+		// we have done a short read and we want more.
+		return true, 0, nil
+	}
+	width += len(clipStart)
+
+	selection, content, _ := bytes.Cut(data, []byte(";"))
+	decoded, err := base64.StdEncoding.DecodeString(string(content))
+	if err != nil {
+		// Not a reply we can make sense of, but it's still no key press.
+		return true, width, unknownOSCSequenceMsg(input[:width])
+	}
+
+	return true, width, ClipboardMsg{
+		Content: string(decoded),
+		Primary: bytes.Equal(selection, []byte{ansi.PrimaryClipboard}),
+	}
+}
@@ -0,0 +1,59 @@
+package tea
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestDetectClipboard(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		width int
+		msg   Msg
+	}{
+		{"bel", "\x1b]52;c;aGk=\x07rest", 12, ClipboardMsg{Content: "hi"}},
+		{"st", "\x1b]52;c;aGk=\x1b\\rest", 13, ClipboardMsg{Content: "hi"}},
+		{"primary", "\x1b]52;p;aGk=\x07", 12, ClipboardMsg{Content: "hi", Primary: true}},
+		{"empty", "\x1b]52;c;\x07", 8, ClipboardMsg{}},
+		{"invalid", "\x1b]52;c;!!\x07", 10, unknownOSCSequenceMsg("\x1b]52;c;!!\x07")},
+		{"incomplete", "\x1b]52;c;aG", 0, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			found, width, msg := detectClipboard([]byte(tc.input))
+			if !found {
+				t.Fatalf("expected a clipboard reply in %q", tc.input)
+			}
+			if width != tc.width {
+				t.Errorf("expected width %d, got %d", tc.width, width)
+			}
+			if !reflect.DeepEqual(msg, tc.msg) {
+				t.Errorf("expected %#v, got %#v", tc.msg, msg)
+			}
+		})
+	}
+
+	if found, _, _ := detectClipboard([]byte("\x1b]")); found {
+		t.Error("expected alt+] not to be a clipboard reply")
+	}
+}
+
+func TestReadClipboardReplyAcrossReads(t *testing.T) {
+	input := io.MultiReader(
+		bytes.NewReader([]byte("\x1b]52;c;aGVs")),
+		bytes.NewReader([]byte("bG8gd29ybGQ=\x07q")),
+	)
+	msgs := testReadInputs(t, input)
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 messages, got %#v", msgs)
+	}
+	if msg, ok := msgs[0].(ClipboardMsg); !ok || msg.Content != "hello world" {
+		t.Errorf("expected the clipboard content, got %#v", msgs[0])
+	}
+	if msg, ok := msgs[1].(KeyMsg); !ok || msg.String() != "q" {
+		t.Errorf("expected q, got %#v", msgs[1])
+	}
+}
@@ -0,0 +1,99 @@
+package tea
+
+import (
+	"context"
+	"time"
+)
+
+// contextCmdMsg is an internal message used to run a command that takes a
+// context.
+type contextCmdMsg func(context.Context) Msg
+
+// ContextCmd produces a command that runs fn with a context that's cancelled
+// when the program exits, whether it quits, is killed or its external
+// context is cancelled. Use it for commands that would otherwise keep
+// running after the program exits, such as HTTP requests and long polls:
+//
+//	func fetch(url string) tea.Cmd {
+//		return tea.ContextCmd(func(ctx context.Context) tea.Msg {
+//			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+//			if err != nil {
+//				return errMsg{err}
+//			}
+//			res, err := http.DefaultClient.Do(req)
+//			if err != nil {
+//				return errMsg{err}
+//			}
+//			defer res.Body.Close()
+//			return statusMsg(res.StatusCode)
+//		})
+//	}
+//
+// The message fn returns is sent to the program, unless it has exited by
+// then. To let such commands unwind before [Program.Run] returns, see
+// [WithShutdownTimeout].
+func ContextCmd(fn func(ctx context.Context) Msg) Cmd {
+	if fn == nil {
+		return nil
+	}
+	return func() Msg {
+		return contextCmdMsg(fn)
+	}
+}
+
+// contextCmd returns a command that runs the context command with the context
+// of the program.
+func (p *Program) contextCmd(fn contextCmdMsg) Cmd {
+	cmd := func() Msg {
+		return fn(p.ctx)
+	}
+	if p.tracer != nil {
+		cmd = p.tracer.cmd(cmd)
+	}
+	return cmd
+}
+
+// goContextCmd runs a command that takes a context in its own goroutine,
+// which Run waits for on shutdown, see WithShutdownTimeout. The returned
+// channel is closed once the command is done and its message sent.
+func (p *Program) goContextCmd(cmd Cmd) <-chan struct{} {
+	done := make(chan struct{})
+	p.goContext(func() {
+		defer close(done)
+		p.Send(cmd())
+	})
+	return done
+}
+
+// goContext runs fn in its own goroutine, which Run waits for on shutdown.
+func (p *Program) goContext(fn func()) {
+	p.contextCmds.Add(1)
+	go func() {
+		defer p.contextCmds.Done()
+
+		defer p.catchCmdPanic()
+
+		fn()
+	}()
+}
+
+// waitForContextCmds waits for the running context commands to return, for up
+// to the shutdown timeout.
+func (p *Program) waitForContextCmds() {
+	if p.shutdownTimeout <= 0 {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.contextCmds.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(p.shutdownTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+	}
+}
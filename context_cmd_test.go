@@ -0,0 +1,179 @@
+package tea
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type contextCmdModel struct {
+	init Cmd
+	msgs []Msg
+}
+
+func (m *contextCmdModel) Init() Cmd {
+	return m.init
+}
+
+func (m *contextCmdModel) Update(msg Msg) (Model, Cmd) {
+	if _, ok := msg.(sequenceMsg); ok {
+		return m, nil
+	}
+	m.msgs = append(m.msgs, msg)
+	if msg == "quit" {
+		return m, Quit
+	}
+	return m, nil
+}
+
+func (m *contextCmdModel) View() string {
+	return ""
+}
+
+func TestContextCmd(t *testing.T) {
+	for _, synchronous := range []bool{false, true} {
+		name := "async"
+		if synchronous {
+			name = "sync"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			m := &contextCmdModel{init: ContextCmd(func(ctx context.Context) Msg {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				return "quit"
+			})}
+
+			var buf, in bytes.Buffer
+			opts := []ProgramOption{WithInput(&in), WithOutput(&buf)}
+			if synchronous {
+				opts = append(opts, WithSynchronousCommands())
+			}
+			if _, err := NewProgram(m, opts...).Run(); err != nil {
+				t.Fatal(err)
+			}
+			if len(m.msgs) != 1 || m.msgs[0] != "quit" {
+				t.Errorf("expected the message of the command, got %v", m.msgs)
+			}
+		})
+	}
+}
+
+func TestContextCmdSequence(t *testing.T) {
+	for _, synchronous := range []bool{false, true} {
+		name := "async"
+		if synchronous {
+			name = "sync"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			m := &contextCmdModel{init: Sequence(
+				ContextCmd(func(context.Context) Msg {
+					time.Sleep(10 * time.Millisecond)
+					return "fetch"
+				}),
+				func() Msg { return "next" },
+				func() Msg { return "quit" },
+			)}
+
+			var buf, in bytes.Buffer
+			opts := []ProgramOption{WithInput(&in), WithOutput(&buf)}
+			if synchronous {
+				opts = append(opts, WithSynchronousCommands())
+			}
+			if _, err := NewProgram(m, opts...).Run(); err != nil {
+				t.Fatal(err)
+			}
+			if want := []Msg{"fetch", "next", "quit"}; !reflect.DeepEqual(m.msgs, want) {
+				t.Errorf("expected messages %v, got %v", want, m.msgs)
+			}
+		})
+	}
+}
+
+func TestContextCmdCancel(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		exit func(*Program, context.CancelFunc)
+		err  error
+	}{
+		{"quit", func(p *Program, _ context.CancelFunc) { p.Quit() }, nil},
+		{"kill", func(p *Program, _ context.CancelFunc) { p.Kill() }, ErrProgramKilled},
+		{"context", func(_ *Program, cancel context.CancelFunc) { cancel() }, context.Canceled},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var unwound atomic.Bool
+			started := make(chan struct{})
+			m := &contextCmdModel{init: ContextCmd(func(ctx context.Context) Msg {
+				close(started)
+				<-ctx.Done()
+				time.Sleep(10 * time.Millisecond)
+				unwound.Store(true)
+				return nil
+			})}
+
+			var buf, in bytes.Buffer
+			p := NewProgram(m,
+				WithInput(&in),
+				WithOutput(&buf),
+				WithContext(ctx),
+				WithShutdownTimeout(time.Second),
+			)
+			go func() {
+				<-started
+				tc.exit(p, cancel)
+			}()
+
+			_, err := p.Run()
+			if !errors.Is(err, tc.err) {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+			if !unwound.Load() {
+				t.Error("expected Run to wait for the command to unwind")
+			}
+		})
+	}
+}
+
+func TestContextCmdShutdownTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	m := &contextCmdModel{init: Batch(
+		ContextCmd(func(context.Context) Msg {
+			<-block // ignores the context
+			return nil
+		}),
+		func() Msg { return "quit" },
+	)}
+
+	var buf, in bytes.Buffer
+	p := NewProgram(m,
+		WithInput(&in),
+		WithOutput(&buf),
+		WithShutdownTimeout(10*time.Millisecond),
+	)
+
+	done := make(chan error)
+	go func() {
+		_, err := p.Run()
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected Run to stop waiting after the shutdown timeout")
+	}
+}
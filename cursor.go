@@ -0,0 +1,160 @@
+package tea
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// CursorShape is the shape of the terminal cursor.
+type CursorShape int
+
+// Cursor shapes.
+const (
+	CursorBlock CursorShape = iota
+	CursorUnderline
+	CursorBar
+)
+
+// Cursor is the terminal cursor a model places in its view, see
+// [CursorModel].
+type Cursor struct {
+	// X and Y are the column and line of the cursor, relative to the top
+	// left corner of the view.
+	X, Y int
+
+	// Shape is the shape of the cursor.
+	Shape CursorShape
+
+	// Blink is whether the cursor blinks.
+	Blink bool
+}
+
+// style returns the DECSCUSR style of the cursor.
+func (c Cursor) style() int {
+	style := int(c.Shape)*2 + 1 //nolint:mnd
+	if !c.Blink {
+		style++
+	}
+	return style
+}
+
+// CursorModel is a model that places the terminal cursor in its view. This is
+// useful for text inputs, so the terminal's own cursor follows the text being
+// edited, as do input methods and screen magnifiers.
+//
+// Cursor is called after every call to View. When it returns a cursor, the
+// renderer moves the cursor there after drawing the frame, and shows it with
+// the given shape. When it returns nil, the cursor is hidden, as it is for
+// models that don't implement CursorModel.
+type CursorModel interface {
+	Model
+
+	// Cursor returns the cursor to show, or nil to hide it.
+	Cursor() *Cursor
+}
+
+// cursorSetter is implemented by renderers that place the cursor of models
+// implementing CursorModel.
+type cursorSetter interface {
+	SetCursor(*Cursor)
+}
+
+// render sends the view of the model, and its cursor, to the renderer.
+func (p *Program) render(model Model) {
+	p.renderer.Write(model.View())
+
+	if r, ok := p.renderer.(cursorSetter); ok {
+		var cursor *Cursor
+		if m, ok := model.(CursorModel); ok {
+			cursor = m.Cursor()
+		}
+		r.SetCursor(cursor)
+	}
+}
+
+// CursorPositionMsg is sent with the position of the cursor the terminal
+// reports in reply to [RequestCursorPosition]. The position is relative to
+// the top left corner of the terminal window, not the view.
+type CursorPositionMsg struct {
+	X, Y int
+}
+
+// requestCursorPositionMsg is an internal message used to request the
+// position of the cursor.
+type requestCursorPositionMsg struct{}
+
+// RequestCursorPosition produces a command that asks the terminal for the
+// position of the cursor (DSR). The reply is sent to the program as a
+// [CursorPositionMsg].
+//
+// Terminals report the cursor on the first line the same way they report F3
+// with modifiers, so such key presses are taken for the reply while the
+// program waits for it.
+func RequestCursorPosition() Cmd {
+	return func() Msg {
+		return requestCursorPositionMsg{}
+	}
+}
+
+var cursorPositionRe = regexp.MustCompile(`^\x1b\[(\d+);(\d+)R`)
+
+// detectCursorPosition detects a cursor position report:
+//
+//	CSI Pr ; Pc R
+//
+// Reports for the first line look the same as F3 with modifiers, e.g.
+// CSI 1 ; 2 R is also shift+F3, so they are detected as keys and told apart
+// by the program, see resolveCursorPosition.
+func detectCursorPosition(input []byte) (hasCPR bool, width int, msg Msg) {
+	m := cursorPositionRe.FindSubmatch(input)
+	if m == nil {
+		return false, 0, nil
+	}
+	row, err1 := strconv.Atoi(string(m[1]))
+	col, err2 := strconv.Atoi(string(m[2]))
+	if err1 != nil || err2 != nil || row == 1 {
+		return false, 0, nil
+	}
+	return true, len(m[0]), CursorPositionMsg{X: col - 1, Y: row - 1}
+}
+
+// resolveCursorPosition turns the keys that look like cursor position
+// reports for the first line into reports while the program is waiting for
+// one.
+func (p *Program) resolveCursorPosition(msg Msg) Msg {
+	switch msg := msg.(type) {
+	case CursorPositionMsg:
+		if p.cursorRequests > 0 {
+			p.cursorRequests--
+		}
+	case KeyMsg:
+		if p.cursorRequests == 0 {
+			break
+		}
+		if pos, ok := cursorPositionKey(Key(msg)); ok {
+			p.cursorRequests--
+			return pos
+		}
+	}
+	return msg
+}
+
+// cursorPositionKey returns the cursor position report for the first line,
+// CSI 1 ; Pc R, that is parsed as the given key, if any.
+func cursorPositionKey(k Key) (CursorPositionMsg, bool) {
+	switch {
+	case k.Type == KeyF15 && !k.Alt && k.Mod == 0:
+		// CSI 1 ; 2 R
+		return CursorPositionMsg{X: 1}, true
+	case k.Type == KeyF3 && k.Mod != 0:
+		// CSI 1 ; Pc R, where Pc - 1 are the modifiers.
+		return CursorPositionMsg{X: int(k.Mod)}, true
+	case k.Type == KeyF3 && k.Alt:
+		// CSI 1 ; 3 R
+		return CursorPositionMsg{X: 2}, true
+	case k.Type == KeyF3:
+		// CSI 1 ; 1 R
+		return CursorPositionMsg{}, true
+	}
+	return CursorPositionMsg{}, false
+}
@@ -0,0 +1,100 @@
+package tea
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/bubbletea/vt"
+)
+
+func TestDetectCursorPosition(t *testing.T) {
+	w, msg := detectOneMsg([]byte("\x1b[5;40Ra"), false)
+	if w != 7 || msg != (CursorPositionMsg{X: 39, Y: 4}) {
+		t.Errorf("expected the cursor position, got %d %#v", w, msg)
+	}
+
+	tests := []struct {
+		input string
+		pos   CursorPositionMsg
+	}{
+		{"\x1b[1;1R", CursorPositionMsg{X: 0}},
+		{"\x1b[1;2R", CursorPositionMsg{X: 1}},
+		{"\x1b[1;3R", CursorPositionMsg{X: 2}},
+		{"\x1b[1;5R", CursorPositionMsg{X: 4}},
+		{"\x1b[1;80R", CursorPositionMsg{X: 79}},
+	}
+	for _, tc := range tests {
+		_, key := detectOneMsg([]byte(tc.input), false)
+
+		p := &Program{}
+		if msg := p.resolveCursorPosition(key); !reflect.DeepEqual(msg, key) {
+			t.Errorf("%q: expected a key when no report was requested, got %#v", tc.input, msg)
+		}
+
+		p.cursorRequests = 1
+		if msg := p.resolveCursorPosition(key); msg != tc.pos {
+			t.Errorf("%q: expected %#v, got %#v", tc.input, tc.pos, msg)
+		}
+		if p.cursorRequests != 0 {
+			t.Errorf("%q: expected the request to be answered", tc.input)
+		}
+	}
+}
+
+func TestStandardRendererCursor(t *testing.T) {
+	for _, cells := range []bool{false, true} {
+		for _, alt := range []bool{false, true} {
+			name := "inline"
+			if alt {
+				name = "altscreen"
+			}
+			if cells {
+				name += "_cells"
+			}
+
+			t.Run(name, func(t *testing.T) {
+				term := vt.New(20, 5)
+				r := newRenderer(term, false, 60, cells).(*standardRenderer)
+				r.HandleMessage(WindowSizeMsg{Width: 20, Height: 5})
+				r.HideCursor()
+				if alt {
+					r.EnterAltScreen()
+				}
+
+				frame := func(view string, c *Cursor) {
+					t.Helper()
+					r.Write(view)
+					r.SetCursor(c)
+					r.flush()
+				}
+				expectCursor := func(x, y int, visible bool) {
+					t.Helper()
+					if cx, cy := term.Cursor(); cx != x || cy != y {
+						t.Errorf("expected the cursor at %d,%d, got %d,%d", x, y, cx, cy)
+					}
+					if term.CursorVisible() != visible {
+						t.Errorf("expected the cursor to be visible: %v", visible)
+					}
+				}
+
+				frame("name: bob\nage: 42\nok", &Cursor{X: 9, Y: 0, Shape: CursorBar})
+				expectCursor(9, 0, true)
+
+				// The frame is drawn in place even though the cursor moved.
+				frame("name: bobby\nage: 42\nok", &Cursor{X: 11, Y: 0, Shape: CursorBar})
+				expectCursor(11, 0, true)
+
+				// Only the cursor moves.
+				frame("name: bobby\nage: 42\nok", &Cursor{X: 5, Y: 1})
+				expectCursor(5, 1, true)
+
+				frame("name: bobby\nage: 43\nok", nil)
+				expectCursor(0, 2, false)
+
+				if got, want := term.String(), "name: bobby\nage: 43\nok\n\n"; got != want {
+					t.Errorf("expected screen:\n%q\ngot:\n%q", want, got)
+				}
+			})
+		}
+	}
+}
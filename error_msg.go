@@ -0,0 +1,122 @@
+package tea
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// ErrorSource is where an error reported with an [ErrorMsg] comes from.
+type ErrorSource int
+
+// Error sources.
+const (
+	// ErrorSourceCmd is a command made with [TryCmd].
+	ErrorSourceCmd ErrorSource = iota
+
+	// ErrorSourceResize is getting the size of the terminal.
+	ErrorSourceResize
+
+	// ErrorSourceInput is reading input. No more input is read after such
+	// an error.
+	ErrorSourceInput
+
+	// ErrorSourceRenderer is writing to the terminal.
+	ErrorSourceRenderer
+
+	// ErrorSourceExec is running a process with [Exec] without a callback.
+	ErrorSourceExec
+)
+
+// String returns the name of the error source.
+func (s ErrorSource) String() string {
+	switch s {
+	case ErrorSourceCmd:
+		return "cmd"
+	case ErrorSourceResize:
+		return "resize"
+	case ErrorSourceInput:
+		return "input"
+	case ErrorSourceRenderer:
+		return "renderer"
+	case ErrorSourceExec:
+		return "exec"
+	}
+	return "unknown"
+}
+
+// ErrorMsg is sent with an error that doesn't have to end the program, see
+// [WithErrorMsgs] and [TryCmd].
+type ErrorMsg struct {
+	// Err is the error.
+	Err error
+
+	// Source is where the error comes from.
+	Source ErrorSource
+}
+
+// Error returns the error message, prefixed with its source.
+func (m ErrorMsg) Error() string {
+	return m.Source.String() + ": " + m.Err.Error()
+}
+
+// Unwrap returns the error.
+func (m ErrorMsg) Unwrap() error {
+	return m.Err
+}
+
+// TryCmd produces a command that runs fn and sends the message it returns,
+// or an [ErrorMsg] if it returns an error, so failed commands report errors
+// the same way:
+//
+//	func save(path string, data []byte) tea.Cmd {
+//		return tea.TryCmd(func() (tea.Msg, error) {
+//			if err := os.WriteFile(path, data, 0o600); err != nil {
+//				return nil, err
+//			}
+//			return savedMsg{}, nil
+//		})
+//	}
+func TryCmd(fn func() (Msg, error)) Cmd {
+	if fn == nil {
+		return nil
+	}
+	return func() Msg {
+		msg, err := fn()
+		if err != nil {
+			return ErrorMsg{Err: err, Source: ErrorSourceCmd}
+		}
+		return msg
+	}
+}
+
+// reportError sends an error to the model as an ErrorMsg, with
+// WithErrorMsgs, or ends the program with it.
+func (p *Program) reportError(source ErrorSource, err error) {
+	if p.startupOptions.has(withErrorMsgs) {
+		p.Send(ErrorMsg{Err: err, Source: source})
+		return
+	}
+	select {
+	case <-p.ctx.Done():
+	case p.errs <- err:
+	}
+}
+
+// errorWriter reports errors writing to the terminal as ErrorMsgs. Only the
+// first of consecutive errors is reported.
+type errorWriter struct {
+	w       io.Writer
+	p       *Program
+	failing atomic.Bool
+}
+
+func (w *errorWriter) Write(b []byte) (int, error) {
+	n, err := w.w.Write(b)
+	if err == nil {
+		w.failing.Store(false)
+	} else if !w.failing.Swap(true) {
+		// Don't block the renderer while the program is busy.
+		go w.p.Send(ErrorMsg{Err: err, Source: ErrorSourceRenderer})
+	}
+	return n, err //nolint:wrapcheck
+}
@@ -0,0 +1,106 @@
+package tea
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"testing"
+	"testing/iotest"
+)
+
+type errorMsgModel struct {
+	init Cmd
+	errs []ErrorMsg
+}
+
+func (m *errorMsgModel) Init() Cmd {
+	return m.init
+}
+
+func (m *errorMsgModel) Update(msg Msg) (Model, Cmd) {
+	if msg, ok := msg.(ErrorMsg); ok {
+		m.errs = append(m.errs, msg)
+		return m, Quit
+	}
+	return m, nil
+}
+
+func (m *errorMsgModel) View() string {
+	return "view"
+}
+
+var errTest = errors.New("test error")
+
+func TestTryCmd(t *testing.T) {
+	ok := TryCmd(func() (Msg, error) { return "ok", nil })
+	if msg := ok(); msg != "ok" {
+		t.Errorf("expected the message, got %v", msg)
+	}
+
+	failed := TryCmd(func() (Msg, error) { return "ok", errTest })
+	msg, isErr := failed().(ErrorMsg)
+	if !isErr || msg.Source != ErrorSourceCmd || !errors.Is(msg, errTest) {
+		t.Errorf("expected a command error, got %#v", msg)
+	}
+	if want := "cmd: test error"; msg.Error() != want {
+		t.Errorf("expected %q, got %q", want, msg.Error())
+	}
+
+	if TryCmd(nil) != nil {
+		t.Error("expected no command")
+	}
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errTest
+}
+
+func TestErrorMsgs(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		init   Cmd
+		opts   []ProgramOption
+		source ErrorSource
+	}{
+		{
+			name:   "input",
+			opts:   []ProgramOption{WithInput(iotest.ErrReader(errTest)), WithOutput(&bytes.Buffer{})},
+			source: ErrorSourceInput,
+		},
+		{
+			name:   "renderer",
+			opts:   []ProgramOption{WithInput(&bytes.Buffer{}), WithOutput(failingWriter{})},
+			source: ErrorSourceRenderer,
+		},
+		{
+			name:   "exec",
+			init:   ExecProcess(exec.Command("invalid"), nil),
+			opts:   []ProgramOption{WithInput(&bytes.Buffer{}), WithOutput(&bytes.Buffer{})},
+			source: ErrorSourceExec,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := &errorMsgModel{init: tc.init}
+			p := NewProgram(m, append(tc.opts, WithErrorMsgs())...)
+			if _, err := p.Run(); err != nil {
+				t.Fatal(err)
+			}
+			if len(m.errs) == 0 {
+				t.Fatal("expected an error message")
+			}
+			if m.errs[0].Source != tc.source {
+				t.Errorf("expected an error from %v, got %v", tc.source, m.errs[0].Source)
+			}
+		})
+	}
+}
+
+func TestErrorMsgsDisabled(t *testing.T) {
+	p := NewProgram(&errorMsgModel{}, WithInput(iotest.ErrReader(errTest)), WithOutput(&bytes.Buffer{}))
+	if _, err := p.Run(); !errors.Is(err, errTest) {
+		t.Errorf("expected the input error to end the program, got %v", err)
+	}
+}
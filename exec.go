@@ -100,11 +100,19 @@ func (c *osExecCommand) SetStderr(w io.Writer) {
 
 // exec runs an ExecCommand and delivers the results to the program as a Msg.
 func (p *Program) exec(c ExecCommand, fn ExecCallback) {
-	if err := p.ReleaseTerminal(); err != nil {
-		// If we can't release input, abort.
-		if fn != nil {
+	// done reports the result of the process to the program.
+	done := func(err error) {
+		switch {
+		case fn != nil:
 			go p.Send(fn(err))
+		case err != nil && p.startupOptions.has(withErrorMsgs):
+			go p.Send(ErrorMsg{Err: err, Source: ErrorSourceExec})
 		}
+	}
+
+	if err := p.ReleaseTerminal(); err != nil {
+		// If we can't release input, abort.
+		done(err)
 		return
 	}
 
@@ -115,15 +123,11 @@ func (p *Program) exec(c ExecCommand, fn ExecCallback) {
 	// Execute system command.
 	if err := c.Run(); err != nil {
 		_ = p.RestoreTerminal() // also try to restore the terminal.
-		if fn != nil {
-			go p.Send(fn(err))
-		}
+		done(err)
 		return
 	}
 
 	// Have the program re-capture input.
 	err := p.RestoreTerminal()
-	if fn != nil {
-		go p.Send(fn(err))
-	}
+	done(err)
 }
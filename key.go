@@ -6,6 +6,7 @@ import (
 	"io"
 	"regexp"
 	"strings"
+	"time"
 	"unicode/utf8"
 )
 
@@ -56,6 +57,25 @@ type Key struct {
 	Runes []rune
 	Alt   bool
 	Paste bool
+
+	// Mod contains the full set of modifiers held down with the key. It is
+	// only reported by terminals with keyboard enhancements enabled (see
+	// [WithKeyboardEnhancements] and [WithModifyOtherKeys]); keys decoded
+	// from legacy sequences only set Alt and encode ctrl and shift in the key
+	// type.
+	Mod KeyMod
+
+	// EventType reports whether the key was pressed, repeated or released.
+	// Repeat and release events are only sent when the [ReportEventTypes]
+	// keyboard enhancement is enabled.
+	EventType KeyEventType
+
+	// ShiftedRune is the character the key produces with shift held down,
+	// and BaseRune is the key's character in the standard PC-101 layout.
+	// Both are only set when the [ReportAlternateKeys] keyboard enhancement
+	// is enabled and the terminal reports them.
+	ShiftedRune rune
+	BaseRune    rune
 }
 
 // String returns a friendly string representation for a key. It's safe (and
@@ -66,9 +86,19 @@ type Key struct {
 //	// Output: enter
 func (k Key) String() (str string) {
 	var buf strings.Builder
+	mods := k.Mod &^ (ModCapsLock | ModNumLock)
 	if k.Alt {
-		buf.WriteString("alt+")
+		mods |= ModAlt
+	}
+	// Don't repeat modifiers that are already part of the key's name, such
+	// as the ctrl in ctrl+up.
+	mods &^= keyTypeMods[k.Type]
+	if k.Type == KeyRunes && mods&^(ModShift|ModAlt) == 0 {
+		// Shift is already reflected in the runes, e.g. "A" rather than
+		// "shift+a".
+		mods &^= ModShift
 	}
+	buf.WriteString(mods.String())
 	if k.Type == KeyRunes {
 		if k.Paste {
 			// Note: bubbles/keys bindings currently do string compares to
@@ -91,6 +121,78 @@ func (k Key) String() (str string) {
 	return ""
 }
 
+// KeyMod represents the modifier keys held down with a key. Modifiers are
+// treated as bits and can be combined.
+type KeyMod int
+
+// Modifier keys. The values match the modifier bits of the kitty keyboard
+// protocol.
+const (
+	ModShift KeyMod = 1 << iota
+	ModAlt
+	ModCtrl
+	ModSuper
+	ModHyper
+	ModMeta
+	ModCapsLock
+	ModNumLock
+)
+
+// Contains reports whether m contains all the given modifiers.
+func (m KeyMod) Contains(mods KeyMod) bool {
+	return m&mods == mods
+}
+
+// String returns the modifiers as a key prefix, such as "alt+ctrl+". Caps
+// lock and num lock are not included.
+func (m KeyMod) String() string {
+	var buf strings.Builder
+	for _, mod := range modNames {
+		if m.Contains(mod.mod) {
+			buf.WriteString(mod.name)
+			buf.WriteByte('+')
+		}
+	}
+	return buf.String()
+}
+
+// modNames lists the modifier names in the order they appear in key strings.
+// Alt comes first for compatibility with the legacy "alt+ctrl+a" form.
+var modNames = []struct {
+	mod  KeyMod
+	name string
+}{
+	{ModAlt, "alt"},
+	{ModCtrl, "ctrl"},
+	{ModShift, "shift"},
+	{ModSuper, "super"},
+	{ModHyper, "hyper"},
+	{ModMeta, "meta"},
+}
+
+// KeyEventType indicates whether a key was pressed, repeated or released.
+type KeyEventType int
+
+// Key event types.
+const (
+	KeyPress KeyEventType = iota
+	KeyRepeat
+	KeyRelease
+)
+
+func (e KeyEventType) String() string {
+	switch e {
+	case KeyPress:
+		return "press"
+	case KeyRepeat:
+		return "repeat"
+	case KeyRelease:
+		return "release"
+	default:
+		return ""
+	}
+}
+
 // KeyType indicates the key pressed, such as KeyEnter or KeyBreak or KeyCtrlC.
 // All other keys will be type KeyRunes. To get the rune value, check the Rune
 // method on a Key struct, or use the Key.String() method:
@@ -350,6 +452,32 @@ var keyNames = map[KeyType]string{
 	KeyF20:            "f20",
 }
 
+// keyTypeMods holds the modifiers that are implied by the name of a key type,
+// such as ctrl and shift for KeyCtrlShiftUp.
+var keyTypeMods = func() map[KeyType]KeyMod {
+	m := map[KeyType]KeyMod{}
+	for t, name := range keyNames {
+		var mods KeyMod
+		for {
+			switch {
+			case strings.HasPrefix(name, "ctrl+"):
+				mods |= ModCtrl
+				name = name[len("ctrl+"):]
+				continue
+			case strings.HasPrefix(name, "shift+"):
+				mods |= ModShift
+				name = name[len("shift+"):]
+				continue
+			}
+			break
+		}
+		if mods != 0 {
+			m[t] = mods
+		}
+	}
+	return m
+}()
+
 // Sequence mappings.
 var sequences = map[string]Key{
 	// Arrow keys
@@ -551,18 +679,53 @@ func (u unknownCSISequenceMsg) String() string {
 	return fmt.Sprintf("?CSI%+v?", []byte(u)[2:])
 }
 
+// unknownOSCSequenceMsg is reported by the input reader when an
+// unrecognized OSC sequence is detected on the input.
+type unknownOSCSequenceMsg []byte
+
+func (u unknownOSCSequenceMsg) String() string {
+	return fmt.Sprintf("?OSC%+v?", []byte(u)[2:])
+}
+
+// unknownDCSSequenceMsg is reported by the input reader when an
+// unrecognized DCS sequence is detected on the input.
+type unknownDCSSequenceMsg []byte
+
+func (u unknownDCSSequenceMsg) String() string {
+	return fmt.Sprintf("?DCS%+v?", []byte(u)[2:])
+}
+
 var spaceRunes = []rune{' '}
 
 // readAnsiInputs reads keypress and mouse inputs from a TTY and produces messages
 // containing information about the key or mouse events accordingly.
-func readAnsiInputs(ctx context.Context, msgs chan<- Msg, input io.Reader) error {
+//
+// If escTimeout is set, an escape character at the end of the input read is
+// held back for up to escTimeout, waiting for the rest of the sequence.
+func readAnsiInputs(ctx context.Context, msgs chan<- Msg, input io.Reader, parser *inputParser, escTimeout time.Duration) error {
 	var buf [256]byte
 
+	read := input.Read
+	var escReader *timeoutReader
+	if escTimeout > 0 {
+		escReader = newTimeoutReader(input)
+		defer escReader.close()
+		read = escReader.Read
+	}
+
 	var leftOverFromPrevIteration []byte
+	var holdingEsc bool
 loop:
 	for {
-		// Read and block.
-		numBytes, err := input.Read(buf[:])
+		// Read and block, or wait for the rest of an escape sequence.
+		var numBytes int
+		var err error
+		if holdingEsc {
+			numBytes, err = escReader.readTimeout(buf[:], escTimeout)
+			holdingEsc = false
+		} else {
+			numBytes, err = read(buf[:])
+		}
 		if err != nil {
 			return fmt.Errorf("error reading input: %w", err)
 		}
@@ -582,8 +745,17 @@ loop:
 
 		var i, w int
 		for i, w = 0, 0; i < len(b); i += w {
+			if escReader != nil && numBytes > 0 && len(b)-i == 1 && b[i] == '\x1b' {
+				// A trailing escape character, which may be the start of
+				// a sequence split across reads. Wait for the rest of it,
+				// after which it's the Escape key.
+				leftOverFromPrevIteration = []byte{'\x1b'}
+				holdingEsc = true
+				continue loop
+			}
+
 			var msg Msg
-			w, msg = detectOneMsg(b[i:], canHaveMoreData)
+			w, msg = parser.detectOneMsg(b[i:], canHaveMoreData)
 			if w == 0 {
 				// Expecting more bytes beyond the current buffer. Try waiting
 				// for more input.
@@ -606,12 +778,100 @@ loop:
 	}
 }
 
+// timeoutReader reads input in the background, so that reads can time out.
+type timeoutReader struct {
+	reads chan readResult
+	done  chan struct{}
+	rest  []byte
+	err   error
+}
+
+// readResult is the result of a read of a timeoutReader.
+type readResult struct {
+	b   []byte
+	err error
+}
+
+func newTimeoutReader(r io.Reader) *timeoutReader {
+	tr := &timeoutReader{
+		reads: make(chan readResult),
+		done:  make(chan struct{}),
+	}
+	go func() {
+		for {
+			var buf [256]byte
+			n, err := r.Read(buf[:])
+			select {
+			case tr.reads <- readResult{b: buf[:n], err: err}:
+			case <-tr.done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return tr
+}
+
+// Read reads the input, blocking until some is available.
+func (r *timeoutReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if len(r.rest) == 0 {
+		res := <-r.reads
+		if res.err != nil {
+			r.err = res.err
+			return 0, res.err
+		}
+		r.rest = res.b
+	}
+	n := copy(p, r.rest)
+	r.rest = r.rest[n:]
+	return n, nil
+}
+
+// readTimeout reads the input, waiting for up to timeout for some to be
+// available. It returns 0 if there's none, or if reading fails, in which case
+// the error is returned by the next read.
+func (r *timeoutReader) readTimeout(p []byte, timeout time.Duration) (int, error) {
+	if r.err != nil {
+		return 0, nil
+	}
+	if len(r.rest) == 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		select {
+		case res := <-r.reads:
+			if res.err != nil {
+				r.err = res.err
+				return 0, nil
+			}
+			r.rest = res.b
+		case <-t.C:
+			return 0, nil
+		}
+	}
+	return r.Read(p)
+}
+
+// close stops reading the input once the read in progress, if any, returns.
+func (r *timeoutReader) close() {
+	close(r.done)
+}
+
 var (
 	unknownCSIRe  = regexp.MustCompile(`^\x1b\[[\x30-\x3f]*[\x20-\x2f]*[\x40-\x7e]`)
 	mouseSGRRegex = regexp.MustCompile(`(\d+);(\d+);(\d+)([Mm])`)
 )
 
+// detectOneMsg detects one message using the built-in key sequences.
 func detectOneMsg(b []byte, canHaveMoreData bool) (w int, msg Msg) {
+	return defaultParser.detectOneMsg(b, canHaveMoreData)
+}
+
+func (p *inputParser) detectOneMsg(b []byte, canHaveMoreData bool) (w int, msg Msg) {
 	// Detect mouse events.
 	// X10 mouse events have a length of 6 bytes
 	const mouseEventX10Len = 6
@@ -635,6 +895,20 @@ func detectOneMsg(b []byte, canHaveMoreData bool) (w int, msg Msg) {
 		return w, msg
 	}
 
+	// Detect clipboard replies.
+	var foundClip bool
+	foundClip, w, msg = detectClipboard(b)
+	if foundClip {
+		return w, msg
+	}
+
+	// Detect replies to terminal queries sent as device control strings.
+	var foundDCS bool
+	foundDCS, w, msg = detectDCSReply(b)
+	if foundDCS {
+		return w, msg
+	}
+
 	// Detect bracketed paste.
 	var foundbp bool
 	foundbp, w, msg = detectBracketedPaste(b)
@@ -642,11 +916,18 @@ func detectOneMsg(b []byte, canHaveMoreData bool) (w int, msg Msg) {
 		return w, msg
 	}
 
+	// Detect cursor position reports.
+	var foundCPR bool
+	foundCPR, w, msg = detectCursorPosition(b)
+	if foundCPR {
+		return w, msg
+	}
+
 	// Detect escape sequence and control characters other than NUL,
 	// possibly with an escape character in front to mark the Alt
 	// modifier.
 	var foundSeq bool
-	foundSeq, w, msg = detectSequence(b)
+	foundSeq, w, msg = p.detectSequence(b)
 	if foundSeq {
 		return w, msg
 	}
@@ -0,0 +1,362 @@
+package tea
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// KeyboardEnhancements is a set of kitty keyboard protocol progressive
+// enhancements. Enhancements are treated as bits and can be combined.
+//
+// See https://sw.kovidgoyal.net/kitty/keyboard-protocol/
+type KeyboardEnhancements int
+
+// Keyboard enhancements. The values match the kitty keyboard protocol
+// progressive enhancement flags.
+const (
+	// DisambiguateEscapeCodes reports keys that are ambiguous in the legacy
+	// encoding, such as ctrl+i and tab or shift+enter and enter, as distinct
+	// escape sequences.
+	DisambiguateEscapeCodes KeyboardEnhancements = 1 << iota
+
+	// ReportEventTypes reports key repeat and key release events in addition
+	// to key presses.
+	ReportEventTypes
+
+	// ReportAlternateKeys reports the shifted and base layout keys along with
+	// the key itself.
+	ReportAlternateKeys
+
+	// ReportAllKeysAsEscapeCodes reports all keys, including plain text keys
+	// and lone modifiers, as escape sequences.
+	ReportAllKeysAsEscapeCodes
+
+	// ReportAssociatedText reports the text a key produces along with the key.
+	ReportAssociatedText
+)
+
+// kittyKeyCodes maps kitty keyboard protocol key codes to key types. Codes
+// that aren't listed here are reported as runes.
+var kittyKeyCodes = map[int]KeyType{
+	9:   KeyTab,
+	13:  KeyEnter,
+	27:  KeyEscape,
+	127: KeyBackspace,
+
+	57376: KeyF13,
+	57377: KeyF14,
+	57378: KeyF15,
+	57379: KeyF16,
+	57380: KeyF17,
+	57381: KeyF18,
+	57382: KeyF19,
+	57383: KeyF20,
+
+	// Keypad keys.
+	57414: KeyEnter,
+	57417: KeyLeft,
+	57418: KeyRight,
+	57419: KeyUp,
+	57420: KeyDown,
+	57421: KeyPgUp,
+	57422: KeyPgDown,
+	57423: KeyHome,
+	57424: KeyEnd,
+	57425: KeyInsert,
+	57426: KeyDelete,
+}
+
+// kittyKeypadRunes maps kitty keypad key codes that produce text to their
+// runes.
+var kittyKeypadRunes = map[int]rune{
+	57399: '0',
+	57400: '1',
+	57401: '2',
+	57402: '3',
+	57403: '4',
+	57404: '5',
+	57405: '6',
+	57406: '7',
+	57407: '8',
+	57408: '9',
+	57409: '.',
+	57410: '/',
+	57411: '*',
+	57412: '-',
+	57413: '+',
+	57415: '=',
+}
+
+// csiTildeKeys maps the number of a CSI number ~ sequence to a key type.
+var csiTildeKeys = map[int]KeyType{
+	2:  KeyInsert,
+	3:  KeyDelete,
+	5:  KeyPgUp,
+	6:  KeyPgDown,
+	7:  KeyHome,
+	8:  KeyEnd,
+	11: KeyF1,
+	12: KeyF2,
+	13: KeyF3,
+	14: KeyF4,
+	15: KeyF5,
+	17: KeyF6,
+	18: KeyF7,
+	19: KeyF8,
+	20: KeyF9,
+	21: KeyF10,
+	23: KeyF11,
+	24: KeyF12,
+	25: KeyF13,
+	26: KeyF14,
+	28: KeyF15,
+	29: KeyF16,
+	31: KeyF17,
+	32: KeyF18,
+	33: KeyF19,
+	34: KeyF20,
+}
+
+// csiLetterKeys maps the final byte of a CSI 1 ; modifiers letter sequence
+// to a key type.
+var csiLetterKeys = map[byte]KeyType{
+	'A': KeyUp,
+	'B': KeyDown,
+	'C': KeyRight,
+	'D': KeyLeft,
+	'F': KeyEnd,
+	'H': KeyHome,
+	'P': KeyF1,
+	'Q': KeyF2,
+	'R': KeyF3,
+	'S': KeyF4,
+}
+
+// modifiedKeyTypes maps a key type and a combination of ctrl and shift to
+// the legacy key type that carries those modifiers in its name, so keys
+// reported with modifier parameters match the same key types as keys
+// decoded from legacy sequences.
+var modifiedKeyTypes = map[KeyType]map[KeyMod]KeyType{
+	KeyUp:     {ModCtrl: KeyCtrlUp, ModShift: KeyShiftUp, ModCtrl | ModShift: KeyCtrlShiftUp},
+	KeyDown:   {ModCtrl: KeyCtrlDown, ModShift: KeyShiftDown, ModCtrl | ModShift: KeyCtrlShiftDown},
+	KeyRight:  {ModCtrl: KeyCtrlRight, ModShift: KeyShiftRight, ModCtrl | ModShift: KeyCtrlShiftRight},
+	KeyLeft:   {ModCtrl: KeyCtrlLeft, ModShift: KeyShiftLeft, ModCtrl | ModShift: KeyCtrlShiftLeft},
+	KeyHome:   {ModCtrl: KeyCtrlHome, ModShift: KeyShiftHome, ModCtrl | ModShift: KeyCtrlShiftHome},
+	KeyEnd:    {ModCtrl: KeyCtrlEnd, ModShift: KeyShiftEnd, ModCtrl | ModShift: KeyCtrlShiftEnd},
+	KeyPgUp:   {ModCtrl: KeyCtrlPgUp},
+	KeyPgDown: {ModCtrl: KeyCtrlPgDown},
+	KeyTab:    {ModShift: KeyShiftTab},
+}
+
+// ctrlRuneKeys maps a rune to the control key type it produces with ctrl
+// held down. Runes whose control key has a different name, such as i (tab)
+// or m (enter), are left out so they stay distinguishable.
+var ctrlRuneKeys = func() map[rune]KeyType {
+	m := map[rune]KeyType{}
+	for t := keyNUL; t <= keyUS; t++ {
+		name := keyNames[t]
+		if !strings.HasPrefix(name, "ctrl+") {
+			continue
+		}
+		r, w := utf8.DecodeRuneInString(name[len("ctrl+"):])
+		if w == len(name)-len("ctrl+") {
+			m[r] = t
+		}
+	}
+	return m
+}()
+
+// parseKittyKey decodes a key from a complete CSI sequence using the kitty
+// keyboard protocol encoding:
+//
+//	CSI code[:shifted[:base]] [; modifiers[:event] [; text]] u
+//	CSI number ; modifiers[:event] ~
+//	CSI 1 ; modifiers[:event] {ABCDFHPQS}
+//
+// The same encoding is used by xterm's formatOtherKeys mode. Keys reported
+// by xterm's modifyOtherKeys mode are decoded too:
+//
+//	CSI 27 ; modifiers ; code ~
+//
+// It reports false if the sequence isn't a key sequence.
+func parseKittyKey(seq []byte) (Key, bool) {
+	if len(seq) < 3 { //nolint:mnd
+		return Key{}, false
+	}
+	final := seq[len(seq)-1]
+	params := strings.Split(string(seq[2:len(seq)-1]), ";")
+
+	var mods KeyMod
+	var event KeyEventType
+	if len(params) > 1 {
+		var ok bool
+		mods, event, ok = parseKittyModifiers(params[1])
+		if !ok {
+			return Key{}, false
+		}
+	}
+
+	var k Key
+	switch {
+	case final == 'u':
+		codes := strings.Split(params[0], ":")
+		code, err := strconv.Atoi(codes[0])
+		if err != nil {
+			return Key{}, false
+		}
+		if len(codes) > 1 && codes[1] != "" {
+			shifted, err := strconv.Atoi(codes[1])
+			if err != nil {
+				return Key{}, false
+			}
+			k.ShiftedRune = rune(shifted)
+		}
+		if len(codes) > 2 { //nolint:mnd
+			base, err := strconv.Atoi(codes[2])
+			if err != nil {
+				return Key{}, false
+			}
+			k.BaseRune = rune(base)
+		}
+
+		var text []rune
+		if len(params) > 2 { //nolint:mnd
+			for _, c := range strings.Split(params[2], ":") {
+				r, err := strconv.Atoi(c)
+				if err != nil {
+					return Key{}, false
+				}
+				text = append(text, rune(r))
+			}
+		}
+
+		if !kittyCodeKey(&k, code, mods, text) {
+			return Key{}, false
+		}
+
+	case final == '~':
+		n, err := strconv.Atoi(params[0])
+		if err != nil {
+			return Key{}, false
+		}
+		if n == modifyOtherKeysNumber && len(params) == 3 { //nolint:mnd
+			code, err := strconv.Atoi(params[2])
+			if err != nil || !modifyOtherKeysCode(&k, code, mods) {
+				return Key{}, false
+			}
+			break
+		}
+		t, ok := csiTildeKeys[n]
+		if !ok {
+			return Key{}, false
+		}
+		k.Type = t
+
+	default:
+		t, ok := csiLetterKeys[final]
+		if !ok || params[0] != "1" || len(params) != 2 { //nolint:mnd
+			return Key{}, false
+		}
+		k.Type = t
+	}
+
+	k.Mod = mods
+	k.Alt = mods.Contains(ModAlt)
+	k.EventType = event
+	if t, ok := modifiedKeyTypes[k.Type][mods&(ModCtrl|ModShift)]; ok && mods&(ModSuper|ModHyper|ModMeta) == 0 {
+		k.Type = t
+	}
+	return k, true
+}
+
+// kittyCodeKey fills in the key type and runes for a kitty key code. It
+// reports false for codes that don't map to a key, such as lone modifier
+// keys.
+func kittyCodeKey(k *Key, code int, mods KeyMod, text []rune) bool {
+	if t, ok := kittyKeyCodes[code]; ok {
+		k.Type = t
+		return true
+	}
+	if r, ok := kittyKeypadRunes[code]; ok {
+		code = int(r)
+	}
+	if code == ' ' {
+		k.Type = KeySpace
+		k.Runes = spaceRunes
+		return true
+	}
+	r := rune(code)
+	if r < ' ' || (r >= 57344 && r <= 63743) || !utf8.ValidRune(r) { //nolint:mnd
+		// Control characters and the private use area, which kitty uses
+		// for functional keys we don't support.
+		return false
+	}
+
+	if mods&(ModCtrl|ModSuper|ModHyper|ModMeta) == ModCtrl && mods&ModShift == 0 {
+		if t, ok := ctrlRuneKeys[r]; ok {
+			k.Type = t
+			return true
+		}
+	}
+
+	k.Type = KeyRunes
+	switch {
+	case mods&(ModCtrl|ModSuper|ModHyper|ModMeta) != 0:
+		// The key doesn't produce text; report the unshifted key.
+		k.Runes = []rune{r}
+	case len(text) > 0:
+		k.Runes = text
+	case mods.Contains(ModShift) && k.ShiftedRune != 0:
+		k.Runes = []rune{k.ShiftedRune}
+	case mods.Contains(ModShift) != mods.Contains(ModCapsLock):
+		k.Runes = []rune{unicode.ToUpper(r)}
+	default:
+		k.Runes = []rune{r}
+	}
+	return true
+}
+
+// modifyOtherKeysNumber is the number of the CSI number ~ sequences of
+// xterm's modifyOtherKeys mode.
+const modifyOtherKeysNumber = 27
+
+// modifyOtherKeysCode fills in the key type and runes for the code of a key
+// reported by xterm's modifyOtherKeys mode. Unlike kitty, xterm reports the
+// shifted character of shifted keys, so shifted letters are reported as the
+// unshifted letter, with the shifted one in ShiftedRune, for ctrl+shift+a to
+// be the same key in both.
+func modifyOtherKeysCode(k *Key, code int, mods KeyMod) bool {
+	if r := rune(code); mods.Contains(ModShift) && unicode.IsUpper(r) {
+		k.ShiftedRune = r
+		code = int(unicode.ToLower(r))
+	}
+	return kittyCodeKey(k, code, mods, nil)
+}
+
+// parseKittyModifiers decodes a modifiers[:event] parameter.
+func parseKittyModifiers(param string) (KeyMod, KeyEventType, bool) {
+	parts := strings.Split(param, ":")
+	var mods KeyMod
+	if parts[0] != "" {
+		m, err := strconv.Atoi(parts[0])
+		if err != nil || m < 1 {
+			return 0, 0, false
+		}
+		mods = KeyMod(m - 1)
+	}
+	event := KeyPress
+	if len(parts) > 1 {
+		switch parts[1] {
+		case "", "1":
+		case "2":
+			event = KeyRepeat
+		case "3":
+			event = KeyRelease
+		default:
+			return 0, 0, false
+		}
+	}
+	return mods, event, true
+}
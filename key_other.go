@@ -6,8 +6,9 @@ package tea
 import (
 	"context"
 	"io"
+	"time"
 )
 
-func readInputs(ctx context.Context, msgs chan<- Msg, input io.Reader) error {
-	return readAnsiInputs(ctx, msgs, input)
+func readInputs(ctx context.Context, msgs chan<- Msg, input io.Reader, parser *inputParser, escTimeout time.Duration) error {
+	return readAnsiInputs(ctx, msgs, input, parser, escTimeout)
 }
@@ -0,0 +1,89 @@
+package tea
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// baseKeyTypes maps the names of keys without modifiers, such as "up" and
+// "enter", to their key types.
+var baseKeyTypes = func() map[string]KeyType {
+	m := map[string]KeyType{"space": KeySpace}
+	for t, name := range keyNames {
+		if t == KeyRunes || keyTypeMods[t] != 0 {
+			continue
+		}
+		m[name] = t
+	}
+	return m
+}()
+
+// ParseKey parses a key in the form returned by [Key.String], such as
+// "ctrl+c", "alt+enter" or "ctrl+shift+left", into a key. The key is decoded
+// the same way as keys read from the terminal, so its String method returns
+// the same string as that of the key pressed:
+//
+//	k, _ := tea.ParseKey("shift+ctrl+up")
+//	fmt.Println(k)
+//	// Output: ctrl+shift+up
+//
+// Modifiers are any of "alt", "ctrl", "shift", "super", "hyper" and "meta",
+// followed by a plus sign, in any order. The key is either the name of a key,
+// such as "enter", "pgup" or "f5", "space" for the space bar, or a single
+// character. Shifted letters are reported as uppercase characters, so
+// "shift+a" is the same key as "A".
+func ParseKey(s string) (Key, error) {
+	var mods KeyMod
+	rest := s
+	for {
+		var found bool
+		for _, mod := range modNames {
+			prefix := mod.name + "+"
+			if strings.HasPrefix(rest, prefix) && len(rest) > len(prefix) {
+				mods |= mod.mod
+				rest = rest[len(prefix):]
+				found = true
+			}
+		}
+		if !found {
+			break
+		}
+	}
+
+	k := Key{Alt: mods.Contains(ModAlt)}
+	if t, ok := baseKeyTypes[rest]; ok {
+		k.Type = t
+		if t == KeySpace {
+			k.Runes = spaceRunes
+		}
+		if t, ok := modifiedKeyTypes[t][mods&(ModCtrl|ModShift)]; ok && mods&(ModSuper|ModHyper|ModMeta) == 0 {
+			k.Type = t
+		}
+		k.Mod = mods &^ (ModAlt | keyTypeMods[k.Type])
+		return k, nil
+	}
+
+	r, w := utf8.DecodeRuneInString(rest)
+	if rest == "" || w != len(rest) || r == utf8.RuneError || unicode.IsControl(r) {
+		return Key{}, fmt.Errorf("invalid key %q", s)
+	}
+
+	nonText := ModCtrl | ModSuper | ModHyper | ModMeta
+	if mods&nonText == ModCtrl && mods&ModShift == 0 {
+		if t, ok := ctrlRuneKeys[r]; ok {
+			k.Type = t
+			return k, nil
+		}
+	}
+	if mods&nonText == 0 && mods.Contains(ModShift) {
+		// Shift is reflected in the character.
+		r = unicode.ToUpper(r)
+		mods &^= ModShift
+	}
+	k.Type = KeyRunes
+	k.Runes = []rune{r}
+	k.Mod = mods &^ ModAlt
+	return k, nil
+}
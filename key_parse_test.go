@@ -0,0 +1,120 @@
+package tea
+
+import (
+	"testing"
+)
+
+func TestParseKey(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want string
+	}{
+		{"a", "a"},
+		{"A", "A"},
+		{"shift+a", "A"},
+		{"alt+a", "alt+a"},
+		{"alt+shift+a", "alt+A"},
+		{"ctrl+c", "ctrl+c"},
+		{"alt+ctrl+c", "alt+ctrl+c"},
+		{"ctrl+alt+c", "alt+ctrl+c"},
+		{"ctrl+shift+a", "ctrl+shift+a"},
+		{"ctrl+i", "ctrl+i"},
+		{"super+a", "super+a"},
+		{"enter", "enter"},
+		{"alt+enter", "alt+enter"},
+		{"ctrl+enter", "ctrl+enter"},
+		{"shift+tab", "shift+tab"},
+		{"ctrl+shift+left", "ctrl+shift+left"},
+		{"shift+ctrl+left", "ctrl+shift+left"},
+		{"ctrl+super+left", "ctrl+super+left"},
+		{"space", " "},
+		{" ", " "},
+		{"alt+space", "alt+ "},
+		{"+", "+"},
+		{"ctrl++", "ctrl++"},
+		{"f12", "f12"},
+		{"ctrl+@", "ctrl+@"},
+		{"世", "世"},
+	} {
+		k, err := ParseKey(tc.in)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.in, err)
+			continue
+		}
+		if got := k.String(); got != tc.want {
+			t.Errorf("%q: expected %q, got %q", tc.in, tc.want, got)
+		}
+	}
+
+	for _, in := range []string{"", "ctrl+", "foo", "ctrl+foo", "cmd+a", "\x01"} {
+		if k, err := ParseKey(in); err == nil {
+			t.Errorf("%q: expected an error, got %v", in, k)
+		}
+	}
+}
+
+func TestParseKeyRoundTrip(t *testing.T) {
+	// Every named key parses back into the same key.
+	for typ, name := range keyNames {
+		if typ == KeyRunes {
+			continue
+		}
+		k, err := ParseKey(name)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", name, err)
+			continue
+		}
+		if k.Type != typ || k.String() != name {
+			t.Errorf("%q: expected key type %d, got %d (%q)", name, typ, k.Type, k)
+		}
+	}
+
+	// So do keys read from the terminal.
+	for seq, key := range extSequences {
+		k, err := ParseKey(key.String())
+		if err != nil {
+			t.Errorf("%q: unexpected error parsing %q: %v", seq, key, err)
+			continue
+		}
+		if k.String() != key.String() {
+			t.Errorf("%q: expected %q, got %q", seq, key, k)
+		}
+	}
+	for _, seq := range []string{
+		"\x1b[97;5u",    // ctrl+a
+		"\x1b[97;7u",    // alt+ctrl+a
+		"\x1b[97;6u",    // ctrl+shift+a
+		"\x1b[105;5u",   // ctrl+i
+		"\x1b[13;3u",    // alt+enter
+		"\x1b[13;5u",    // ctrl+enter
+		"\x1b[1;6D",     // ctrl+shift+left
+		"\x1b[1;13D",    // ctrl+super+left
+		"\x1b[32;5u",    // ctrl+space
+		"\x1b[15;2~",    // shift+f5
+		"\x1b[97;9u",    // super+a
+		"\x1b[97:65;2u", // shift+a with the shifted key
+		"\x1b[57399;1u", // keypad 0
+		"\x1b[9;2u",     // shift+tab
+		"\x1b[97;65u",   // caps lock+a
+		"\x1b[97;1:3u",  // a released
+		"\x1b[91;5u",    // ctrl+[
+		"\x1b[64;5u",    // ctrl+@
+		"\x1b[1;8D",     // alt+ctrl+shift+left
+		"\x1b[246;2u",   // shift+ö
+	} {
+		_, _, msg := detectSequence([]byte(seq))
+		key, ok := msg.(KeyMsg)
+		if !ok {
+			t.Errorf("%q: expected a key, got %T", seq, msg)
+			continue
+		}
+		k, err := ParseKey(key.String())
+		if err != nil {
+			t.Errorf("%q: unexpected error parsing %q: %v", seq, key, err)
+			continue
+		}
+		if k.String() != key.String() {
+			t.Errorf("%q: expected %q, got %q", seq, key, k)
+		}
+	}
+}
@@ -13,14 +13,7 @@ import (
 // by detectOneMsg.
 var extSequences = func() map[string]Key {
 	s := map[string]Key{}
-	for seq, key := range sequences {
-		key := key
-		s[seq] = key
-		if !key.Alt {
-			key.Alt = true
-			s["\x1b"+seq] = key
-		}
-	}
+	addSequences(s, sequences)
 	for i := keyNUL + 1; i <= keyDEL; i++ {
 		if i == keyESC {
 			continue
@@ -37,11 +30,27 @@ var extSequences = func() map[string]Key {
 	return s
 }()
 
+// addSequences adds the sequences to s, along with their alternatives
+// with an escape character prefixed.
+func addSequences(s map[string]Key, seqs map[string]Key) {
+	for seq, key := range seqs {
+		s[seq] = key
+		if !key.Alt {
+			key.Alt = true
+			s["\x1b"+seq] = key
+		}
+	}
+}
+
 // seqLengths is the sizes of valid sequences, starting with the
 // largest size.
-var seqLengths = func() []int {
+var seqLengths = sequenceLengths(extSequences)
+
+// sequenceLengths returns the sizes of the sequences, starting with the
+// largest size.
+func sequenceLengths(seqs map[string]Key) []int {
 	sizes := map[int]struct{}{}
-	for seq := range extSequences {
+	for seq := range seqs {
 		sizes[len(seq)] = struct{}{}
 	}
 	lsizes := make([]int, 0, len(sizes))
@@ -50,13 +59,44 @@ var seqLengths = func() []int {
 	}
 	sort.Slice(lsizes, func(i, j int) bool { return lsizes[i] > lsizes[j] })
 	return lsizes
-}()
+}
+
+// inputParser parses input into messages. Each program has its own, which
+// may recognize key sequences of its own, see WithKeySequences.
+type inputParser struct {
+	// seqs are the key sequences recognized, and seqLengths their sizes,
+	// starting with the largest size.
+	seqs       map[string]Key
+	seqLengths []int
+}
+
+// defaultParser recognizes the built-in key sequences.
+var defaultParser = &inputParser{seqs: extSequences, seqLengths: seqLengths}
+
+// newInputParser returns a parser recognizing the given key sequences on
+// top of the built-in ones. The built-in sequences are left untouched.
+func newInputParser(extra map[string]Key) *inputParser {
+	if len(extra) == 0 {
+		return defaultParser
+	}
+	seqs := make(map[string]Key, len(extSequences)+2*len(extra)) //nolint:mnd
+	for seq, key := range extSequences {
+		seqs[seq] = key
+	}
+	addSequences(seqs, extra)
+	return &inputParser{seqs: seqs, seqLengths: sequenceLengths(seqs)}
+}
+
+// detectSequence detects a sequence using the built-in key sequences.
+func detectSequence(input []byte) (hasSeq bool, width int, msg Msg) {
+	return defaultParser.detectSequence(input)
+}
 
 // detectSequence uses a longest prefix match over the input
 // sequence and a hash map.
-func detectSequence(input []byte) (hasSeq bool, width int, msg Msg) {
-	seqs := extSequences
-	for _, sz := range seqLengths {
+func (p *inputParser) detectSequence(input []byte) (hasSeq bool, width int, msg Msg) {
+	seqs := p.seqs
+	for _, sz := range p.seqLengths {
 		if sz > len(input) {
 			continue
 		}
@@ -68,6 +108,15 @@ func detectSequence(input []byte) (hasSeq bool, width int, msg Msg) {
 	}
 	// Is this an unknown CSI sequence?
 	if loc := unknownCSIRe.FindIndex(input); loc != nil {
+		// It may be the reply to a terminal query.
+		if reply, ok := parseQueryReply(input[:loc[1]]); ok {
+			return true, loc[1], reply
+		}
+		// It may still be a key using the kitty keyboard protocol
+		// encoding.
+		if key, ok := parseKittyKey(input[:loc[1]]); ok {
+			return true, loc[1], KeyMsg(key)
+		}
 		return true, loc[1], unknownCSISequenceMsg(input[:loc[1]])
 	}
 
@@ -419,6 +419,20 @@ func TestReadInput(t *testing.T) {
 			[]byte{'\x1b', '[', '-', '-', '-', '-', 'X'},
 			[]Msg{unknownCSISequenceMsg([]byte{'\x1b', '[', '-', '-', '-', '-', 'X'})},
 		},
+		// Clipboard replies.
+		{
+			"hi a",
+			[]byte("\x1b]52;c;aGk=\x07a"),
+			[]Msg{
+				ClipboardMsg{Content: "hi"},
+				KeyMsg{Type: KeyRunes, Runes: []rune{'a'}},
+			},
+		},
+		{
+			"copied",
+			[]byte("\x1b]52;p;Y29waWVk\x1b\\"),
+			[]Msg{ClipboardMsg{Content: "copied", Primary: true}},
+		},
 		// Powershell sequences.
 		{
 			"up",
@@ -548,7 +562,57 @@ func TestReadInput(t *testing.T) {
 	}
 }
 
+// slowReader returns one chunk of input per read, after a delay.
+type slowReader struct {
+	chunks []string
+	delay  time.Duration
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	time.Sleep(r.delay)
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.chunks[0])
+	r.chunks = r.chunks[1:]
+	return n, nil
+}
+
+func TestReadInputEscapeTimeout(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		chunks  []string
+		delay   time.Duration
+		timeout time.Duration
+		want    []string
+	}{
+		{"split alt", []string{"\x1b", "a"}, 10 * time.Millisecond, time.Second, []string{"alt+a"}},
+		{"split sequence", []string{"\x1b", "[A"}, 10 * time.Millisecond, time.Second, []string{"up"}},
+		{"split alt without timeout", []string{"\x1b", "a"}, 10 * time.Millisecond, 0, []string{"esc", "a"}},
+		{"escape then key", []string{"\x1b", "a"}, 100 * time.Millisecond, 10 * time.Millisecond, []string{"esc", "a"}},
+		{"escape at end of read", []string{"ab\x1b", "c"}, 100 * time.Millisecond, 10 * time.Millisecond, []string{"ab", "esc", "c"}},
+		{"escape at end of input", []string{"\x1b"}, 10 * time.Millisecond, time.Second, []string{"esc"}},
+		{"double escape", []string{"\x1b", "\x1b"}, 10 * time.Millisecond, time.Second, []string{"alt+esc"}},
+		{"whole sequence", []string{"\x1ba"}, 10 * time.Millisecond, time.Second, []string{"alt+a"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			input := &slowReader{chunks: tc.chunks, delay: tc.delay}
+			var got []string
+			for _, msg := range testReadInputsTimeout(t, input, tc.timeout) {
+				got = append(got, fmt.Sprint(msg))
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
 func testReadInputs(t *testing.T, input io.Reader) []Msg {
+	return testReadInputsTimeout(t, input, 0)
+}
+
+func testReadInputsTimeout(t *testing.T, input io.Reader, escTimeout time.Duration) []Msg {
 	// We'll check that the input reader finishes at the end
 	// without error.
 	var wg sync.WaitGroup
@@ -569,7 +633,7 @@ func testReadInputs(t *testing.T, input io.Reader) []Msg {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		inputErr = readAnsiInputs(ctx, msgsC, input)
+		inputErr = readAnsiInputs(ctx, msgsC, input, defaultParser, escTimeout)
 		msgsC <- nil
 	}()
 
@@ -733,3 +797,191 @@ func BenchmarkDetectSequenceMap(b *testing.B) {
 		}
 	}
 }
+
+func TestKeyStringModifiers(t *testing.T) {
+	tests := []struct {
+		key  Key
+		want string
+	}{
+		{Key{Type: KeyEnter, Mod: ModShift}, "shift+enter"},
+		{Key{Type: KeyRunes, Runes: []rune{'i'}, Mod: ModCtrl}, "ctrl+i"},
+		{Key{Type: KeyRunes, Runes: []rune{'a'}, Mod: ModCtrl | ModShift}, "ctrl+shift+a"},
+		{Key{Type: KeyRunes, Runes: []rune{'A'}, Mod: ModShift}, "A"},
+		{Key{Type: KeyRunes, Runes: []rune{'A'}, Mod: ModShift | ModAlt, Alt: true}, "alt+A"},
+		{Key{Type: KeyCtrlUp, Mod: ModCtrl}, "ctrl+up"},
+		{Key{Type: KeyCtrlA, Mod: ModCtrl | ModAlt, Alt: true}, "alt+ctrl+a"},
+		{Key{Type: KeyUp, Mod: ModCtrl | ModSuper}, "ctrl+super+up"},
+		{Key{Type: KeyRunes, Runes: []rune{'x'}, Mod: ModHyper | ModMeta}, "hyper+meta+x"},
+		{Key{Type: KeyRunes, Runes: []rune{'x'}, Mod: ModCapsLock | ModNumLock}, "x"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.want, func(t *testing.T) {
+			if got := tc.key.String(); got != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDetectKittyKeys(t *testing.T) {
+	tests := []struct {
+		seq  string
+		want Key
+	}{
+		{"\x1b[9u", Key{Type: KeyTab}},
+		{"\x1b[105;5u", Key{Type: KeyRunes, Runes: []rune{'i'}, Mod: ModCtrl}},
+		{"\x1b[97;5u", Key{Type: KeyCtrlA, Mod: ModCtrl}},
+		{"\x1b[97;7u", Key{Type: KeyCtrlA, Mod: ModCtrl | ModAlt, Alt: true}},
+		{"\x1b[13;2u", Key{Type: KeyEnter, Mod: ModShift}},
+		{"\x1b[27u", Key{Type: KeyEscape}},
+		{"\x1b[32;5u", Key{Type: KeySpace, Runes: spaceRunes, Mod: ModCtrl}},
+		{"\x1b[97;2u", Key{Type: KeyRunes, Runes: []rune{'A'}, Mod: ModShift}},
+		{"\x1b[49:33;2u", Key{Type: KeyRunes, Runes: []rune{'!'}, Mod: ModShift, ShiftedRune: '!'}},
+		{"\x1b[1089::99;5u", Key{Type: KeyRunes, Runes: []rune{'с'}, Mod: ModCtrl, BaseRune: 'c'}},
+		{"\x1b[97;9u", Key{Type: KeyRunes, Runes: []rune{'a'}, Mod: ModSuper}},
+		{"\x1b[97;1:2u", Key{Type: KeyRunes, Runes: []rune{'a'}, EventType: KeyRepeat}},
+		{"\x1b[97;1:3u", Key{Type: KeyRunes, Runes: []rune{'a'}, EventType: KeyRelease}},
+		{"\x1b[97;2;65u", Key{Type: KeyRunes, Runes: []rune{'A'}, Mod: ModShift}},
+		{"\x1b[57399u", Key{Type: KeyRunes, Runes: []rune{'0'}}},
+		{"\x1b[57414u", Key{Type: KeyEnter}},
+		{"\x1b[1;5:3A", Key{Type: KeyCtrlUp, Mod: ModCtrl, EventType: KeyRelease}},
+		{"\x1b[1;9A", Key{Type: KeyUp, Mod: ModSuper}},
+		{"\x1b[1;6H", Key{Type: KeyCtrlShiftHome}},
+		{"\x1b[3;1:2~", Key{Type: KeyDelete, EventType: KeyRepeat}},
+		{"\x1b[5;5:1~", Key{Type: KeyCtrlPgUp, Mod: ModCtrl}},
+		{"\x1b[9;2u", Key{Type: KeyShiftTab, Mod: ModShift}},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%q", tc.seq), func(t *testing.T) {
+			w, msg := detectOneMsg([]byte(tc.seq), false)
+			if w != len(tc.seq) {
+				t.Errorf("parser did not consume the entire input: got %d, expected %d", w, len(tc.seq))
+			}
+			if !reflect.DeepEqual(KeyMsg(tc.want), msg) {
+				t.Errorf("expected event %#v, got %#v (%T)", KeyMsg(tc.want), msg, msg)
+			}
+		})
+	}
+
+	// Sequences that aren't keys should still be reported as unknown CSI
+	// sequences.
+	for _, seq := range []string{"\x1b[?1u", "\x1b[57441u", "\x1b[99~", "\x1b[2;5X"} {
+		t.Run(fmt.Sprintf("%q", seq), func(t *testing.T) {
+			_, msg := detectOneMsg([]byte(seq), false)
+			if _, ok := msg.(unknownCSISequenceMsg); !ok {
+				t.Errorf("expected unknown CSI sequence, got %#v (%T)", msg, msg)
+			}
+		})
+	}
+}
+
+func TestDetectModifyOtherKeys(t *testing.T) {
+	tests := []struct {
+		seq  string
+		want string
+	}{
+		{"\x1b[27;5;13~", "ctrl+enter"},
+		{"\x1b[27;2;13~", "shift+enter"},
+		{"\x1b[27;5;9~", "ctrl+tab"},
+		{"\x1b[27;6;9~", "ctrl+shift+tab"},
+		{"\x1b[27;5;105~", "ctrl+i"},
+		{"\x1b[27;5;97~", "ctrl+a"},
+		{"\x1b[27;6;65~", "ctrl+shift+a"},
+		{"\x1b[27;2;65~", "A"},
+		{"\x1b[27;3;97~", "alt+a"},
+		{"\x1b[27;7;97~", "alt+ctrl+a"},
+		{"\x1b[27;5;49~", "ctrl+1"},
+		{"\x1b[27;5;32~", "ctrl+ "},
+		{"\x1b[27;5;127~", "ctrl+backspace"},
+		{"\x1b[27;5;27~", "ctrl+esc"},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%q", tc.seq), func(t *testing.T) {
+			w, msg := detectOneMsg([]byte(tc.seq), false)
+			if w != len(tc.seq) {
+				t.Errorf("parser did not consume the entire input: got %d, expected %d", w, len(tc.seq))
+			}
+			k, ok := msg.(KeyMsg)
+			if !ok {
+				t.Fatalf("expected a key, got %#v (%T)", msg, msg)
+			}
+			if k.String() != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, k.String())
+			}
+
+			// The key is the same as its parsed name.
+			p, err := ParseKey(tc.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.String() != k.String() {
+				t.Errorf("expected the key to match %q, got %q", p, k)
+			}
+		})
+	}
+
+	for _, seq := range []string{"\x1b[27;5~", "\x1b[27;5;x~", "\x1b[27;5;57441~"} {
+		t.Run(fmt.Sprintf("%q", seq), func(t *testing.T) {
+			_, msg := detectOneMsg([]byte(seq), false)
+			if _, ok := msg.(unknownCSISequenceMsg); !ok {
+				t.Errorf("expected unknown CSI sequence, got %#v (%T)", msg, msg)
+			}
+		})
+	}
+}
+
+// keysModel records the keys pressed until q.
+type keysModel struct {
+	keys []string
+}
+
+func (m *keysModel) Init() Cmd { return nil }
+
+func (m *keysModel) Update(msg Msg) (Model, Cmd) {
+	if msg, ok := msg.(KeyMsg); ok {
+		if msg.String() == "q" {
+			return m, Quit
+		}
+		m.keys = append(m.keys, msg.String())
+	}
+	return m, nil
+}
+
+func (m *keysModel) View() string { return "" }
+
+func TestKeySequences(t *testing.T) {
+	custom := map[string]Key{
+		"\x1bOw":  {Type: KeyHome},
+		"\x1b[[A": {Type: KeyF12}, // overrides f1
+		"\x1b[[Z": {Type: KeyF5, Alt: true},
+	}
+	run := func(input string, opts ...ProgramOption) []string {
+		t.Helper()
+		m := &keysModel{}
+		opts = append(opts, WithInput(strings.NewReader(input)), WithOutput(&bytes.Buffer{}))
+		if _, err := NewProgram(m, opts...).Run(); err != nil {
+			t.Fatal(err)
+		}
+		return m.keys
+	}
+
+	want := []string{"home", "alt+home", "f12", "alt+f5", "up"}
+	if got := run("\x1bOw\x1b\x1bOw\x1b[[A\x1b[[Z\x1b[Aq", WithKeySequences(custom)); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected keys %q, got %q", want, got)
+	}
+
+	// Other programs, and the built-in sequences, are left untouched.
+	want = []string{"alt+O", "w", "f1"}
+	if got := run("\x1bOw\x1b[[Aq"); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected keys %q, got %q", want, got)
+	}
+	if _, ok := extSequences["\x1bOw"]; ok {
+		t.Error("expected the built-in sequences to be left untouched")
+	}
+	if !reflect.DeepEqual(seqLengths, sequenceLengths(extSequences)) {
+		t.Error("expected the built-in sequence lengths to be left untouched")
+	}
+	if p := NewProgram(nil); p.parser != defaultParser {
+		t.Error("expected programs without key sequences to use the default parser")
+	}
+}
@@ -7,18 +7,25 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/erikgeiser/coninput"
 	localereader "github.com/mattn/go-localereader"
 	"github.com/muesli/cancelreader"
 )
 
-func readInputs(ctx context.Context, msgs chan<- Msg, input io.Reader) error {
+func readInputs(ctx context.Context, msgs chan<- Msg, input io.Reader, parser *inputParser, escTimeout time.Duration) error {
 	if coninReader, ok := input.(*conInputReader); ok {
 		return readConInputs(ctx, msgs, coninReader)
 	}
+	// Console input events are not raw bytes, so they can't be recorded.
+	if r, ok := input.(*recordingReader); ok {
+		if coninReader, ok := r.CancelReader.(*conInputReader); ok {
+			return readConInputs(ctx, msgs, coninReader)
+		}
+	}
 
-	return readAnsiInputs(ctx, msgs, localereader.NewReader(input))
+	return readAnsiInputs(ctx, msgs, localereader.NewReader(input), parser, escTimeout)
 }
 
 func readConInputs(ctx context.Context, msgsch chan<- Msg, con *conInputReader) error {
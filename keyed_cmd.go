@@ -0,0 +1,137 @@
+package tea
+
+import (
+	"context"
+	"time"
+)
+
+// keyedCmdMsg is an internal message used to run a keyed command.
+type keyedCmdMsg struct {
+	key      string
+	fn       func(context.Context) Msg
+	debounce time.Duration
+	throttle time.Duration
+
+	// done is closed once the command is done, for sequences to wait for
+	// it.
+	done chan struct{}
+}
+
+// keyedResultMsg is an internal message with the result of a keyed command.
+type keyedResultMsg struct {
+	key string
+	cmd *keyedCmd
+	msg Msg
+}
+
+// keyedCmd is a running keyed command.
+type keyedCmd struct {
+	cancel context.CancelFunc
+}
+
+// KeyedCmd produces a command like [ContextCmd] that replaces the running
+// command with the same key: starting it cancels the context of the running
+// command, and drops its message. Use it to only get the result of the latest
+// request, e.g. when searching as the user types:
+//
+//	case tea.KeyMsg:
+//		m.input, _ = m.input.Update(msg)
+//		query := m.input.Value()
+//		return m, tea.KeyedCmd("search", func(ctx context.Context) tea.Msg {
+//			return search(ctx, query)
+//		})
+func KeyedCmd(key string, fn func(ctx context.Context) Msg) Cmd {
+	return keyed(keyedCmdMsg{key: key, fn: fn})
+}
+
+// Debounce produces a keyed command, see [KeyedCmd], that waits for the given
+// duration before running fn. Starting another command with the same key in
+// the meantime cancels it, so fn only runs once the commands stop coming in
+// for the duration, e.g. once the user stops typing:
+//
+//	return m, tea.Debounce("search", 300*time.Millisecond, func(ctx context.Context) tea.Msg {
+//		return search(ctx, query)
+//	})
+func Debounce(key string, d time.Duration, fn func(ctx context.Context) Msg) Cmd {
+	return keyed(keyedCmdMsg{key: key, fn: fn, debounce: d})
+}
+
+// Throttle produces a keyed command, see [KeyedCmd], that runs fn at most once
+// per the given duration. Throttled commands with the same key started within
+// the duration of the last one that ran are dropped, and aren't run once the
+// duration has passed either: the first command wins, not the latest one. To
+// get the result of the latest command, e.g. when searching as the user
+// types, use [Debounce].
+func Throttle(key string, d time.Duration, fn func(ctx context.Context) Msg) Cmd {
+	return keyed(keyedCmdMsg{key: key, fn: fn, throttle: d})
+}
+
+func keyed(msg keyedCmdMsg) Cmd {
+	if msg.fn == nil {
+		return nil
+	}
+	return func() Msg {
+		return msg
+	}
+}
+
+// keyedCmd starts a keyed command, cancelling the running command with the
+// same key, and returns the command to run. It returns nil if the command is
+// throttled.
+func (p *Program) keyedCmd(msg keyedCmdMsg) Cmd {
+	if msg.throttle > 0 {
+		now := time.Now()
+		if until, ok := p.throttled[msg.key]; ok && now.Before(until) {
+			return nil
+		}
+
+		// Forget the keys no longer throttled.
+		for key, until := range p.throttled {
+			if !now.Before(until) {
+				delete(p.throttled, key)
+			}
+		}
+		if p.throttled == nil {
+			p.throttled = map[string]time.Time{}
+		}
+		p.throttled[msg.key] = now.Add(msg.throttle)
+	}
+
+	if running, ok := p.keyedCmds[msg.key]; ok {
+		running.cancel()
+	}
+	if p.keyedCmds == nil {
+		p.keyedCmds = map[string]*keyedCmd{}
+	}
+	ctx, cancel := context.WithCancel(p.ctx)
+	k := &keyedCmd{cancel: cancel}
+	p.keyedCmds[msg.key] = k
+
+	return p.contextCmd(func(context.Context) Msg {
+		if msg.debounce > 0 {
+			timer := time.NewTimer(msg.debounce)
+			defer timer.Stop()
+			select {
+			case <-ctx.Done():
+				return keyedResultMsg{key: msg.key, cmd: k}
+			case <-timer.C:
+			}
+		}
+		return keyedResultMsg{key: msg.key, cmd: k, msg: msg.fn(ctx)}
+	})
+}
+
+// resolveKeyedCmd returns the message of a keyed command, or nil if the
+// command was replaced by another one with the same key.
+func (p *Program) resolveKeyedCmd(msg Msg) Msg {
+	result, ok := msg.(keyedResultMsg)
+	if !ok {
+		return msg
+	}
+	if p.keyedCmds[result.key] != result.cmd {
+		return nil
+	}
+	delete(p.keyedCmds, result.key)
+	result.cmd.cancel()
+	return result.msg
+}
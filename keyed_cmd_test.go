@@ -0,0 +1,173 @@
+package tea
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestKeyedCmd(t *testing.T) {
+	var cancelled atomic.Bool
+	started := make(chan struct{})
+	m := &contextCmdModel{init: KeyedCmd("search", func(ctx context.Context) Msg {
+		close(started)
+		<-ctx.Done()
+		cancelled.Store(true)
+		return "stale"
+	})}
+
+	var buf, in bytes.Buffer
+	p := NewProgram(m, WithInput(&in), WithOutput(&buf), WithShutdownTimeout(time.Second))
+	go func() {
+		<-started
+		p.Send(KeyedCmd("other", func(context.Context) Msg {
+			return "other"
+		})())
+		p.Send(KeyedCmd("search", func(context.Context) Msg {
+			time.Sleep(10 * time.Millisecond)
+			return "quit"
+		})())
+	}()
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []Msg{"other", "quit"}; !reflect.DeepEqual(m.msgs, want) {
+		t.Errorf("expected messages %v, got %v", want, m.msgs)
+	}
+	if !cancelled.Load() {
+		t.Error("expected the replaced command to be cancelled")
+	}
+}
+
+func TestKeyedCmdSequence(t *testing.T) {
+	for _, synchronous := range []bool{false, true} {
+		name := "async"
+		if synchronous {
+			name = "sync"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			m := &contextCmdModel{init: Sequence(
+				KeyedCmd("fetch", func(context.Context) Msg {
+					time.Sleep(10 * time.Millisecond)
+					return "fetch"
+				}),
+				Throttle("throttle", time.Hour, func(context.Context) Msg {
+					return "throttle"
+				}),
+				Throttle("throttle", time.Hour, func(context.Context) Msg {
+					return "throttled"
+				}),
+				func() Msg { return "quit" },
+			)}
+
+			var buf, in bytes.Buffer
+			opts := []ProgramOption{WithInput(&in), WithOutput(&buf)}
+			if synchronous {
+				opts = append(opts, WithSynchronousCommands())
+			}
+			if _, err := NewProgram(m, opts...).Run(); err != nil {
+				t.Fatal(err)
+			}
+			if want := []Msg{"fetch", "throttle", "quit"}; !reflect.DeepEqual(m.msgs, want) {
+				t.Errorf("expected messages %v, got %v", want, m.msgs)
+			}
+		})
+	}
+}
+
+func TestKeyedCmdSequenceFilter(t *testing.T) {
+	m := &contextCmdModel{init: Sequence(
+		KeyedCmd("fetch", func(context.Context) Msg {
+			return "fetch"
+		}),
+		func() Msg { return "quit" },
+	)}
+
+	// The filter drops the messages it doesn't know about, which mustn't
+	// include the ones starting the commands of the sequence.
+	filter := func(_ Model, msg Msg) Msg {
+		switch msg.(type) {
+		case string, QuitMsg, sequenceMsg:
+			return msg
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var buf, in bytes.Buffer
+	p := NewProgram(m, WithInput(&in), WithOutput(&buf), WithContext(ctx), WithFilter(filter))
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []Msg{"fetch", "quit"}; !reflect.DeepEqual(m.msgs, want) {
+		t.Errorf("expected messages %v, got %v", want, m.msgs)
+	}
+}
+
+func TestDebounce(t *testing.T) {
+	var runs atomic.Int32
+	debounce := func(msg Msg) Msg {
+		return Debounce("key", 20*time.Millisecond, func(context.Context) Msg {
+			runs.Add(1)
+			return msg
+		})()
+	}
+	m := &contextCmdModel{}
+
+	var buf, in bytes.Buffer
+	p := NewProgram(m, WithInput(&in), WithOutput(&buf))
+	go func() {
+		for _, msg := range []Msg{"a", "b", "quit"} {
+			p.Send(debounce(msg))
+		}
+	}()
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []Msg{"quit"}; !reflect.DeepEqual(m.msgs, want) {
+		t.Errorf("expected messages %v, got %v", want, m.msgs)
+	}
+	if n := runs.Load(); n != 1 {
+		t.Errorf("expected 1 run, got %d", n)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := &Program{ctx: ctx}
+
+	throttle := func(msg Msg) keyedCmdMsg {
+		return Throttle("key", time.Hour, func(context.Context) Msg {
+			return msg
+		})().(keyedCmdMsg)
+	}
+
+	first := p.keyedCmd(throttle("a"))
+	if first == nil {
+		t.Fatal("expected the first command to run")
+	}
+	if cmd := p.keyedCmd(throttle("b")); cmd != nil {
+		t.Error("expected the second command to be throttled")
+	}
+	if msg := p.resolveKeyedCmd(first()); msg != "a" {
+		t.Errorf("expected the message of the first command, got %v", msg)
+	}
+
+	// Other keys aren't throttled, and keys no longer throttled are
+	// forgotten.
+	p.throttled["expired"] = time.Now().Add(-time.Second)
+	other := Throttle("other", time.Hour, func(context.Context) Msg { return "c" })().(keyedCmdMsg)
+	if cmd := p.keyedCmd(other); cmd == nil {
+		t.Error("expected a command with another key to run")
+	}
+	if _, ok := p.throttled["expired"]; ok || len(p.throttled) != 2 {
+		t.Errorf("expected the expired key to be forgotten, got %v", p.throttled)
+	}
+}
@@ -0,0 +1,199 @@
+package keymap
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Binding binds one or more keys to an action, along with its help.
+type Binding struct {
+	keys     []tea.Key
+	seqs     [][]tea.Key
+	help     Help
+	disabled bool
+	err      error
+}
+
+// Help is the help of a binding.
+type Help struct {
+	// Key is how the keys are shown in help, such as "↑/k". It defaults to
+	// the keys separated by slashes.
+	Key string
+
+	// Desc is the description of the action, such as "move up".
+	Desc string
+}
+
+// BindingOption is an option for a binding.
+type BindingOption func(*Binding)
+
+// NewBinding returns a binding with the given options:
+//
+//	up := keymap.NewBinding(
+//		keymap.WithKeys("up", "k"),
+//		keymap.WithHelp("↑/k", "move up"),
+//	)
+//
+// Invalid keys are reported by [Binding.Err] and [New].
+func NewBinding(opts ...BindingOption) Binding {
+	var b Binding
+	for _, opt := range opts {
+		opt(&b)
+	}
+	return b
+}
+
+// WithKeys sets the keys of a binding, in the form returned by
+// [tea.Key.String], such as "ctrl+c", "alt+enter" or "ctrl+shift+left". See
+// [tea.ParseKey].
+//
+// Keys separated by spaces, such as "g g" or "space f", are sequences of
+// keys, pressed one after the other. Sequences are matched by a [Sequencer].
+func WithKeys(keys ...string) BindingOption {
+	return func(b *Binding) {
+		var errs []error
+		for _, s := range keys {
+			seq, err := parseSequence(s)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if len(seq) == 1 {
+				b.keys = append(b.keys, seq[0])
+			} else {
+				b.seqs = append(b.seqs, seq)
+			}
+		}
+		b.err = errors.Join(errs...)
+	}
+}
+
+// parseSequence parses a key, or a sequence of keys separated by spaces.
+func parseSequence(s string) ([]tea.Key, error) {
+	// A single key may contain a space, as in " " or "ctrl+ ".
+	if k, err := tea.ParseKey(s); err == nil {
+		return []tea.Key{k}, nil
+	}
+	fields := strings.Fields(s)
+	if len(fields) < 2 { //nolint:mnd
+		return nil, fmt.Errorf("invalid key %q", s)
+	}
+	seq := make([]tea.Key, len(fields))
+	for i, f := range fields {
+		k, err := tea.ParseKey(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key sequence %q: %w", s, err)
+		}
+		seq[i] = k
+	}
+	return seq, nil
+}
+
+// WithHelp sets the help of a binding.
+func WithHelp(key, desc string) BindingOption {
+	return func(b *Binding) {
+		b.help = Help{Key: key, Desc: desc}
+	}
+}
+
+// WithDisabled disables a binding.
+func WithDisabled() BindingOption {
+	return func(b *Binding) {
+		b.disabled = true
+	}
+}
+
+// Keys returns the keys of the binding, not including sequences.
+func (b Binding) Keys() []tea.Key {
+	return b.keys
+}
+
+// Sequences returns the key sequences of the binding.
+func (b Binding) Sequences() [][]tea.Key {
+	return b.seqs
+}
+
+// sequences returns the keys and the key sequences of the binding, keys
+// being sequences of one.
+func (b Binding) sequences() [][]tea.Key {
+	seqs := make([][]tea.Key, 0, len(b.keys)+len(b.seqs))
+	for _, k := range b.keys {
+		seqs = append(seqs, []tea.Key{k})
+	}
+	return append(seqs, b.seqs...)
+}
+
+// Help returns the help of the binding.
+func (b Binding) Help() Help {
+	h := b.help
+	if h.Key == "" {
+		var names []string
+		for _, seq := range b.sequences() {
+			names = append(names, sequenceName(seq, keyName))
+		}
+		h.Key = strings.Join(names, "/")
+	}
+	return h
+}
+
+// Err returns the errors parsing the keys of the binding, if any.
+func (b Binding) Err() error {
+	return b.err
+}
+
+// Enabled reports whether the binding is enabled and has keys. Disabled
+// bindings don't match any key and are left out of help.
+func (b Binding) Enabled() bool {
+	return !b.disabled && len(b.keys)+len(b.seqs) > 0
+}
+
+// SetEnabled enables or disables the binding.
+func (b *Binding) SetEnabled(enabled bool) {
+	b.disabled = !enabled
+}
+
+// Matches reports whether the key press matches one of the keys of the
+// binding. Key releases and pasted text never match, nor do sequences.
+func (b Binding) Matches(msg tea.KeyMsg) bool {
+	if !b.Enabled() || msg.Paste || msg.EventType == tea.KeyRelease {
+		return false
+	}
+	s := msg.String()
+	for _, k := range b.keys {
+		if k.String() == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether the key press matches any of the bindings.
+func Matches(msg tea.KeyMsg, bindings ...Binding) bool {
+	for _, b := range bindings {
+		if b.Matches(msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// keyName returns the name of a key shown in help.
+func keyName(k tea.Key) string {
+	if k.Type == tea.KeySpace {
+		return strings.TrimSuffix(k.String(), " ") + "space"
+	}
+	return k.String()
+}
+
+// sequenceName returns the name of a key sequence, the names of its keys
+// separated by spaces.
+func sequenceName(seq []tea.Key, name func(tea.Key) string) string {
+	names := make([]string, len(seq))
+	for i, k := range seq {
+		names[i] = name(k)
+	}
+	return strings.Join(names, " ")
+}
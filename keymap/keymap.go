@@ -0,0 +1,152 @@
+// Package keymap binds keys to actions. Keys are written in the form returned
+// by [tea.Key.String], such as "ctrl+c", "alt+enter" or "ctrl+shift+left",
+// and are matched against key presses however the terminal reports them:
+//
+//	var quit = keymap.NewBinding(
+//		keymap.WithKeys("q", "ctrl+c"),
+//		keymap.WithHelp("q", "quit"),
+//	)
+//
+//	func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+//		switch msg := msg.(type) {
+//		case tea.KeyMsg:
+//			if quit.Matches(msg) {
+//				return m, tea.Quit
+//			}
+//		}
+//		return m, nil
+//	}
+//
+// Bindings are grouped in a [Keymap], which reports conflicting bindings and
+// generates help. Sequences of keys, such as "g g", are matched by a
+// [Sequencer].
+package keymap
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// ConflictError is returned by [New] when two bindings share a key or a key
+// sequence.
+type ConflictError struct {
+	// Key is the key or key sequence bound twice.
+	Key string
+
+	// Bindings are the help of the bindings.
+	Bindings [2]Help
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("key %q is bound to both %q and %q",
+		e.Key, describe(e.Bindings[0]), describe(e.Bindings[1]))
+}
+
+// describe returns the description of a binding, or its keys if it has none.
+func describe(h Help) string {
+	if h.Desc != "" {
+		return h.Desc
+	}
+	return h.Key
+}
+
+// Keymap is a set of bindings.
+type Keymap struct {
+	bindings []*Binding
+}
+
+// New returns a keymap of the given bindings. It returns an error if any of
+// them has invalid keys, or a [ConflictError] for each key or key sequence
+// bound by more than one enabled binding, along with the keymap. A key that
+// starts a longer sequence isn't a conflict, see [Sequencer].
+//
+// The keymap refers to the bindings, so bindings enabled or disabled later
+// on are matched and listed in help accordingly.
+func New(bindings ...*Binding) (*Keymap, error) {
+	var errs []error
+	bound := map[string]*Binding{}
+	for _, b := range bindings {
+		if err := b.Err(); err != nil {
+			errs = append(errs, err)
+		}
+		if !b.Enabled() {
+			continue
+		}
+		for _, seq := range b.sequences() {
+			s := sequenceName(seq, tea.Key.String)
+			if other, ok := bound[s]; ok && other != b {
+				errs = append(errs, &ConflictError{
+					Key:      s,
+					Bindings: [2]Help{other.Help(), b.Help()},
+				})
+				continue
+			}
+			bound[s] = b
+		}
+	}
+	return &Keymap{bindings: bindings}, errors.Join(errs...)
+}
+
+// Match returns the first enabled binding that matches the key press, if
+// any.
+func (m *Keymap) Match(msg tea.KeyMsg) (*Binding, bool) {
+	for _, b := range m.bindings {
+		if b.Matches(msg) {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+// ShortHelp returns the help of the enabled bindings on a single line:
+//
+//	↑/k up • ↓/j down • q quit
+func (m *Keymap) ShortHelp() string {
+	var items []string
+	for _, h := range m.help() {
+		items = append(items, strings.TrimSpace(h.Key+" "+h.Desc))
+	}
+	return strings.Join(items, " • ")
+}
+
+// FullHelp returns the help of the enabled bindings, one per line, with the
+// descriptions aligned:
+//
+//	↑/k  move up
+//	↓/j  move down
+//	q    quit
+func (m *Keymap) FullHelp() string {
+	help := m.help()
+	var width int
+	for _, h := range help {
+		width = max(width, ansi.StringWidth(h.Key))
+	}
+
+	var buf strings.Builder
+	for i, h := range help {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(h.Key)
+		if h.Desc != "" {
+			buf.WriteString(strings.Repeat(" ", width-ansi.StringWidth(h.Key)+2)) //nolint:mnd
+			buf.WriteString(h.Desc)
+		}
+	}
+	return buf.String()
+}
+
+// help returns the help of the enabled bindings.
+func (m *Keymap) help() []Help {
+	var help []Help
+	for _, b := range m.bindings {
+		if b.Enabled() {
+			help = append(help, b.Help())
+		}
+	}
+	return help
+}
@@ -0,0 +1,117 @@
+package keymap
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func key(t *testing.T, s string) tea.KeyMsg {
+	t.Helper()
+	k, err := tea.ParseKey(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tea.KeyMsg(k)
+}
+
+func TestBindingMatches(t *testing.T) {
+	b := NewBinding(WithKeys("ctrl+shift+left", "alt+enter", "K"))
+	if err := b.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		msg  tea.KeyMsg
+		want bool
+	}{
+		{tea.KeyMsg{Type: tea.KeyCtrlShiftLeft}, true},
+		{tea.KeyMsg{Type: tea.KeyLeft, Mod: tea.ModCtrl | tea.ModShift}, true},
+		{tea.KeyMsg{Type: tea.KeyEnter, Alt: true}, true},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'K'}}, true},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}, Mod: tea.ModShift}, false},
+		{tea.KeyMsg{Type: tea.KeyEnter}, false},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'K'}, Paste: true}, false},
+		{tea.KeyMsg{Type: tea.KeyEnter, Alt: true, EventType: tea.KeyRelease}, false},
+		{tea.KeyMsg{Type: tea.KeyEnter, Alt: true, EventType: tea.KeyRepeat}, true},
+	} {
+		if got := b.Matches(tc.msg); got != tc.want {
+			t.Errorf("%q: expected match %v, got %v", tc.msg, tc.want, got)
+		}
+	}
+
+	b.SetEnabled(false)
+	if b.Matches(key(t, "K")) {
+		t.Error("expected a disabled binding not to match")
+	}
+	if !Matches(key(t, "K"), b, NewBinding(WithKeys("K"))) {
+		t.Error("expected any of the bindings to match")
+	}
+}
+
+func TestBindingErr(t *testing.T) {
+	b := NewBinding(WithKeys("ctrl+foo", "q"))
+	if b.Err() == nil {
+		t.Error("expected an error for the invalid key")
+	}
+	if !b.Matches(key(t, "q")) {
+		t.Error("expected the valid keys to match")
+	}
+	if _, err := New(&b); err == nil {
+		t.Error("expected the keymap to report the invalid key")
+	}
+}
+
+func TestKeymap(t *testing.T) {
+	up := NewBinding(WithKeys("up", "k"), WithHelp("↑/k", "move up"))
+	down := NewBinding(WithKeys("down", "j"), WithHelp("↓/j", "move down"))
+	quit := NewBinding(WithKeys("q", "ctrl+c"), WithHelp("", "quit"))
+	hidden := NewBinding(WithKeys("x"), WithDisabled())
+
+	km, err := New(&up, &down, &quit, &hidden)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b, ok := km.Match(key(t, "j")); !ok || b != &down {
+		t.Errorf("expected j to match down, got %v", b)
+	}
+	if _, ok := km.Match(key(t, "x")); ok {
+		t.Error("expected disabled bindings not to match")
+	}
+
+	if want := "↑/k move up • ↓/j move down • q/ctrl+c quit"; km.ShortHelp() != want {
+		t.Errorf("expected short help %q, got %q", want, km.ShortHelp())
+	}
+	want := "↑/k       move up\n" +
+		"↓/j       move down\n" +
+		"q/ctrl+c  quit"
+	if km.FullHelp() != want {
+		t.Errorf("expected full help:\n%s\ngot:\n%s", want, km.FullHelp())
+	}
+
+	// Bindings enabled later on are listed.
+	hidden.SetEnabled(true)
+	if want := "↑/k move up • ↓/j move down • q/ctrl+c quit • x"; km.ShortHelp() != want {
+		t.Errorf("expected short help %q, got %q", want, km.ShortHelp())
+	}
+}
+
+func TestKeymapConflicts(t *testing.T) {
+	left := NewBinding(WithKeys("ctrl+shift+left"), WithHelp("", "select word"))
+	other := NewBinding(WithKeys("shift+ctrl+left", "h"), WithHelp("", "previous tab"))
+	disabled := NewBinding(WithKeys("h"), WithDisabled())
+
+	_, err := New(&left, &other, &disabled)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+	if conflict.Key != "ctrl+shift+left" || conflict.Bindings[0].Desc != "select word" || conflict.Bindings[1].Desc != "previous tab" {
+		t.Errorf("unexpected conflict %+v", conflict)
+	}
+	if want := `key "ctrl+shift+left" is bound to both "select word" and "previous tab"`; err.Error() != want {
+		t.Errorf("expected error %q, got %q", want, err)
+	}
+}
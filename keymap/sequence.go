@@ -0,0 +1,202 @@
+package keymap
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// SequenceMsg is sent by a [Sequencer] in place of the key presses of a
+// sequence of keys bound to a binding.
+type SequenceMsg struct {
+	// Binding is the binding matched.
+	Binding *Binding
+
+	// Keys are the key presses of the sequence.
+	Keys []tea.KeyMsg
+}
+
+// String returns the keys of the sequence separated by spaces, such as
+// "g g".
+func (m SequenceMsg) String() string {
+	return keysString(m.Keys)
+}
+
+// sequenceTimeoutMsg ends the sequence pending in a sequencer, unless more
+// keys were pressed since.
+type sequenceTimeoutMsg struct {
+	s   *Sequencer
+	gen int
+}
+
+// Sequencer matches sequences of key presses, such as "g g" or
+// "space f", against bindings. It's installed as middleware, see
+// [tea.WithMiddleware]:
+//
+//	seq := keymap.NewSequencer(time.Second, &keys.Top, &keys.DeleteLine)
+//	p := tea.NewProgram(model{seq: seq}, tea.WithMiddleware(seq.Middleware()))
+//
+// Key presses that may start a sequence are held back until the sequence is
+// complete, which is then sent to the model as a single [SequenceMsg]. If
+// another key is pressed, or no key is pressed before the timeout, the keys
+// held back are sent to the model as they were, in order. If the keys held
+// back are bound to a binding of their own, as "g" would be along with
+// "g g", the SequenceMsg of that binding is sent instead.
+//
+// Bindings of single keys are matched too, and sent as SequenceMsg of one
+// key.
+type Sequencer struct {
+	bindings []*Binding
+	timeout  time.Duration
+
+	mtx     sync.Mutex
+	pending []tea.KeyMsg
+	gen     int
+}
+
+// NewSequencer returns a sequencer matching the given bindings. Sequences
+// left incomplete end after the timeout, or not if it's zero.
+func NewSequencer(timeout time.Duration, bindings ...*Binding) *Sequencer {
+	return &Sequencer{bindings: bindings, timeout: timeout}
+}
+
+// Pending returns the keys of the sequence pending, separated by spaces, or
+// an empty string if there's none. Views use it to show a hint, such as
+// "g-", while a sequence is being typed.
+func (s *Sequencer) Pending() string {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return keysString(s.pending)
+}
+
+// Middleware returns the middleware matching key sequences.
+func (s *Sequencer) Middleware() tea.Middleware {
+	return func(next tea.UpdateFunc) tea.UpdateFunc {
+		return func(m tea.Model, msg tea.Msg) (tea.Model, tea.Cmd) {
+			msgs, cmd := s.update(msg)
+			cmds := []tea.Cmd{cmd}
+			for _, msg := range msgs {
+				var c tea.Cmd
+				m, c = next(m, msg)
+				cmds = append(cmds, c)
+			}
+			return m, tea.Batch(cmds...)
+		}
+	}
+}
+
+// update returns the messages to send to the model in place of msg, along
+// with the command ending the sequence pending on timeout, if any.
+func (s *Sequencer) update(msg tea.Msg) ([]tea.Msg, tea.Cmd) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	switch msg := msg.(type) {
+	case sequenceTimeoutMsg:
+		if msg.s != s {
+			// A timeout of another sequencer.
+			break
+		}
+		if msg.gen != s.gen || len(s.pending) == 0 {
+			return nil, nil
+		}
+		return s.flush(), nil
+
+	case tea.KeyMsg:
+		if msg.Paste || msg.EventType == tea.KeyRelease {
+			break
+		}
+
+		var msgs []tea.Msg
+		for {
+			keys := append(s.pending[:len(s.pending):len(s.pending)], msg)
+			b, prefix := s.match(keys)
+			if prefix {
+				s.pending = keys
+				s.gen++
+				return msgs, s.tick()
+			}
+			if b != nil {
+				s.pending = nil
+				return append(msgs, SequenceMsg{Binding: b, Keys: keys}), nil
+			}
+			if len(s.pending) == 0 {
+				return append(msgs, msg), nil
+			}
+			// The key doesn't continue the sequence pending, which ends
+			// here. The key may start another one.
+			msgs = append(msgs, s.flush()...)
+		}
+	}
+	return []tea.Msg{msg}, nil
+}
+
+// match returns the binding whose sequence is keys, if any, and whether keys
+// are the start of a longer sequence.
+func (s *Sequencer) match(keys []tea.KeyMsg) (match *Binding, prefix bool) {
+	for _, b := range s.bindings {
+		if !b.Enabled() {
+			continue
+		}
+		for _, seq := range b.sequences() {
+			if len(seq) < len(keys) || !sequenceHasPrefix(seq, keys) {
+				continue
+			}
+			if len(seq) > len(keys) {
+				prefix = true
+			} else if match == nil {
+				match = b
+			}
+		}
+	}
+	return match, prefix
+}
+
+// flush ends the sequence pending and returns the messages to send in its
+// place: the SequenceMsg of the binding of the keys pending, if any, or the
+// keys.
+func (s *Sequencer) flush() []tea.Msg {
+	keys := s.pending
+	s.pending = nil
+	if b, _ := s.match(keys); b != nil {
+		return []tea.Msg{SequenceMsg{Binding: b, Keys: keys}}
+	}
+	msgs := make([]tea.Msg, len(keys))
+	for i, k := range keys {
+		msgs[i] = k
+	}
+	return msgs
+}
+
+// tick returns the command ending the sequence pending on timeout.
+func (s *Sequencer) tick() tea.Cmd {
+	if s.timeout <= 0 {
+		return nil
+	}
+	gen := s.gen
+	return tea.Tick(s.timeout, func(time.Time) tea.Msg {
+		return sequenceTimeoutMsg{s: s, gen: gen}
+	})
+}
+
+// sequenceHasPrefix reports whether the sequence starts with the key
+// presses.
+func sequenceHasPrefix(seq []tea.Key, keys []tea.KeyMsg) bool {
+	for i, k := range keys {
+		if seq[i].String() != k.String() {
+			return false
+		}
+	}
+	return true
+}
+
+// keysString returns the key presses separated by spaces.
+func keysString(keys []tea.KeyMsg) string {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = keyName(tea.Key(k))
+	}
+	return strings.Join(names, " ")
+}
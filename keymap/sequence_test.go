@@ -0,0 +1,186 @@
+package keymap
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sequenceRecorder records the messages reaching the model as strings.
+type sequenceRecorder struct {
+	update tea.UpdateFunc
+	got    []string
+}
+
+func newSequenceRecorder(s *Sequencer) *sequenceRecorder {
+	r := &sequenceRecorder{}
+	r.update = s.Middleware()(func(m tea.Model, msg tea.Msg) (tea.Model, tea.Cmd) {
+		switch msg := msg.(type) {
+		case SequenceMsg:
+			r.got = append(r.got, fmt.Sprintf("%s: %s", msg.Binding.Help().Desc, msg))
+		case tea.KeyMsg:
+			r.got = append(r.got, msg.String())
+		default:
+			r.got = append(r.got, fmt.Sprintf("%T", msg))
+		}
+		return m, nil
+	})
+	return r
+}
+
+// send sends the message through the middleware and returns the command
+// ending the sequence pending, if any.
+func (r *sequenceRecorder) send(msg tea.Msg) tea.Cmd {
+	_, cmd := r.update(nil, msg)
+	return cmd
+}
+
+func (r *sequenceRecorder) press(t *testing.T, keys ...string) {
+	t.Helper()
+	for _, s := range keys {
+		r.send(key(t, s))
+	}
+}
+
+func TestSequencer(t *testing.T) {
+	top := NewBinding(WithKeys("g g"), WithHelp("", "top"))
+	bottom := NewBinding(WithKeys("G"), WithHelp("", "bottom"))
+	del := NewBinding(WithKeys("d d", "d w"), WithHelp("", "delete"))
+	find := NewBinding(WithKeys("space f f", "space f g"), WithHelp("", "find"))
+	if err := find.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		keys []string
+		want []string
+	}{
+		{"sequence", []string{"g", "g"}, []string{"top: g g"}},
+		{"single key", []string{"G"}, []string{"bottom: G"}},
+		{"alternative", []string{"d", "w"}, []string{"delete: d w"}},
+		{"leader", []string{" ", "f", "g"}, []string{"find: space f g"}},
+		{"incomplete", []string{" ", "f"}, nil},
+		{"unbound", []string{"x"}, []string{"x"}},
+		{"mismatch", []string{"g", "x"}, []string{"g", "x"}},
+		{"mismatch starting another", []string{"g", "d", "d"}, []string{"g", "delete: d d"}},
+		{"mismatch bound", []string{"d", "G"}, []string{"d", "bottom: G"}},
+		{"mismatch pending", []string{"g", "d"}, []string{"g"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := newSequenceRecorder(NewSequencer(0, &top, &bottom, &del, &find))
+			r.press(t, tc.keys...)
+			if !reflect.DeepEqual(r.got, tc.want) {
+				t.Errorf("expected %q, got %q", tc.want, r.got)
+			}
+		})
+	}
+
+	t.Run("pending", func(t *testing.T) {
+		s := NewSequencer(0, &top, &find)
+		r := newSequenceRecorder(s)
+		r.press(t, " ", "f")
+		if s.Pending() != "space f" {
+			t.Errorf("expected pending %q, got %q", "space f", s.Pending())
+		}
+		r.press(t, "f")
+		if s.Pending() != "" {
+			t.Errorf("expected no pending keys, got %q", s.Pending())
+		}
+	})
+
+	t.Run("passthrough", func(t *testing.T) {
+		r := newSequenceRecorder(NewSequencer(0, &top))
+		r.press(t, "g")
+		r.send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g"), Paste: true})
+		r.send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g"), EventType: tea.KeyRelease})
+		r.send(tea.WindowSizeMsg{})
+		r.press(t, "g")
+		want := []string{"[g]", "g", "tea.WindowSizeMsg", "top: g g"}
+		if !reflect.DeepEqual(r.got, want) {
+			t.Errorf("expected %q, got %q", want, r.got)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		del := NewBinding(WithKeys("d d"), WithDisabled())
+		r := newSequenceRecorder(NewSequencer(0, &del))
+		r.press(t, "d", "d")
+		if want := []string{"d", "d"}; !reflect.DeepEqual(r.got, want) {
+			t.Errorf("expected %q, got %q", want, r.got)
+		}
+	})
+}
+
+func TestSequencerTimeout(t *testing.T) {
+	quit := NewBinding(WithKeys("q"), WithHelp("", "quit"))
+	quitAll := NewBinding(WithKeys("q a"), WithHelp("", "quit all"))
+	top := NewBinding(WithKeys("g g"), WithHelp("", "top"))
+
+	s := NewSequencer(10*time.Millisecond, &quit, &quitAll, &top)
+	r := newSequenceRecorder(s)
+
+	// Keys pending are sent as they were on timeout.
+	cmd := r.send(key(t, "g"))
+	if cmd == nil {
+		t.Fatal("expected a timeout command")
+	}
+	r.send(cmd())
+	if want := []string{"g"}; !reflect.DeepEqual(r.got, want) {
+		t.Fatalf("expected %q, got %q", want, r.got)
+	}
+
+	// Keys bound to a binding of their own are sent as a sequence.
+	r.got = nil
+	r.send(r.send(key(t, "q"))())
+	if want := []string{"quit: q"}; !reflect.DeepEqual(r.got, want) {
+		t.Fatalf("expected %q, got %q", want, r.got)
+	}
+
+	// Timeouts of sequences ended since are ignored.
+	r.got = nil
+	stale := r.send(key(t, "g"))
+	r.press(t, "g")
+	next := r.send(key(t, "q"))
+	r.send(stale())
+	if s.Pending() != "q" {
+		t.Errorf("expected pending %q, got %q", "q", s.Pending())
+	}
+	r.press(t, "a")
+	r.send(next())
+	if want := []string{"top: g g", "quit all: q a"}; !reflect.DeepEqual(r.got, want) {
+		t.Errorf("expected %q, got %q", want, r.got)
+	}
+}
+
+func TestSequenceBindings(t *testing.T) {
+	b := NewBinding(WithKeys("g g", "ctrl+ ", "space f", "ctrl+x ctrl+s"))
+	if err := b.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Keys()) != 1 || len(b.Sequences()) != 3 {
+		t.Errorf("expected 1 key and 3 sequences, got %v and %v", b.Keys(), b.Sequences())
+	}
+	if want := "ctrl+space/g g/space f/ctrl+x ctrl+s"; b.Help().Key != want {
+		t.Errorf("expected help %q, got %q", want, b.Help().Key)
+	}
+	if b.Matches(key(t, "g")) {
+		t.Error("expected sequences not to match single keys")
+	}
+
+	if err := NewBinding(WithKeys("g foo")).Err(); err == nil {
+		t.Error("expected an error for the invalid sequence")
+	}
+
+	other := NewBinding(WithKeys("g  g"), WithHelp("", "top"))
+	prefix := NewBinding(WithKeys("g"), WithHelp("", "go"))
+	if _, err := New(&b, &prefix); err != nil {
+		t.Errorf("expected no conflict with a prefix, got %v", err)
+	}
+	if _, err := New(&b, &other); err == nil || err.Error() != `key "g g" is bound to both "ctrl+space/g g/space f/ctrl+x ctrl+s" and "top"` {
+		t.Errorf("expected a conflict, got %v", err)
+	}
+}
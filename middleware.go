@@ -0,0 +1,22 @@
+package tea
+
+// UpdateFunc updates a model in response to a message, as [Model.Update]
+// does.
+type UpdateFunc func(Model, Msg) (Model, Cmd)
+
+// Middleware wraps the update of the model, see [WithMiddleware]. It's given
+// the next UpdateFunc in the chain and returns one that calls it, or not:
+//
+//	func logger(next tea.UpdateFunc) tea.UpdateFunc {
+//		return func(m tea.Model, msg tea.Msg) (tea.Model, tea.Cmd) {
+//			log.Printf("msg: %T", msg)
+//			return next(m, msg)
+//		}
+//	}
+//
+// The innermost UpdateFunc handles Bubble Tea's own messages, such as
+// [QuitMsg], and then calls the model's Update, so middleware sees those
+// messages too and can replace or drop them. The internal messages that start
+// the commands of [ContextCmd] and [KeyedCmd] are handled before the
+// middleware, so that a [Sequence] never waits on a dropped one.
+type Middleware func(next UpdateFunc) UpdateFunc
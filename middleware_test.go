@@ -0,0 +1,124 @@
+package tea
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type middlewareModel []string
+
+func (m middlewareModel) Init() Cmd {
+	return func() Msg { return "init" }
+}
+
+func (m middlewareModel) Update(msg Msg) (Model, Cmd) {
+	if s, ok := msg.(string); ok {
+		m = append(m, s)
+		if s == "done" {
+			return m, Quit
+		}
+		return m, func() Msg { return "done" }
+	}
+	return m, nil
+}
+
+func (m middlewareModel) View() string {
+	return fmt.Sprint(len(m))
+}
+
+func TestMiddleware(t *testing.T) {
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next UpdateFunc) UpdateFunc {
+			return func(m Model, msg Msg) (Model, Cmd) {
+				if s, ok := msg.(string); ok {
+					calls = append(calls, name+":"+s)
+				}
+				return next(m, msg)
+			}
+		}
+	}
+
+	// upper replaces the message, and drops the command of init.
+	upper := func(next UpdateFunc) UpdateFunc {
+		return func(m Model, msg Msg) (Model, Cmd) {
+			if msg == "init" {
+				m, cmd := next(m, "INIT")
+				if cmd == nil {
+					t.Error("expected a command from the model")
+				}
+				return m, func() Msg { return "done" }
+			}
+			return next(m, msg)
+		}
+	}
+
+	var buf, in bytes.Buffer
+	p := NewProgram(middlewareModel(nil),
+		WithInput(&in),
+		WithOutput(&buf),
+		WithoutRenderer(),
+		WithSynchronousCommands(),
+		WithMiddleware(trace("a"), upper),
+		WithFilter(func(_ Model, msg Msg) Msg {
+			if s, ok := msg.(string); ok {
+				calls = append(calls, "filter:"+s)
+			}
+			return msg
+		}),
+		WithMiddleware(trace("b")),
+	)
+
+	m, err := p.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := (middlewareModel{"INIT", "done"}); !reflect.DeepEqual(m, want) {
+		t.Errorf("expected model %v, got %v", want, m)
+	}
+	want := []string{"a:init", "filter:INIT", "b:INIT", "a:done", "filter:done", "b:done"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("expected calls %v, got %v", want, calls)
+	}
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	var quits int
+	var buf, in bytes.Buffer
+	p := NewProgram(middlewareModel(nil),
+		WithInput(&in),
+		WithOutput(&buf),
+		WithoutRenderer(),
+		WithSynchronousCommands(),
+		WithMiddleware(func(next UpdateFunc) UpdateFunc {
+			return func(m Model, msg Msg) (Model, Cmd) {
+				switch msg.(type) {
+				case QuitMsg:
+					// Keep running the first time, and quit on a message
+					// the model doesn't know about.
+					quits++
+					if quits == 1 {
+						return m, func() Msg { return 42 }
+					}
+				case int:
+					return m, Quit
+				}
+				return next(m, msg)
+			}
+		}),
+	)
+
+	m, err := p.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quits != 2 {
+		t.Errorf("expected 2 quits, got %d", quits)
+	}
+	if want := (middlewareModel{"init", "done"}); !reflect.DeepEqual(m, want) {
+		t.Errorf("expected model %v, got %v", want, m)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"sync/atomic"
+	"time"
 )
 
 // ProgramOption is used to set options when initializing a Program. Program can
@@ -23,6 +24,74 @@ func WithContext(ctx context.Context) ProgramOption {
 	}
 }
 
+// WithShutdownTimeout makes [Program.Run] wait, for up to the given
+// duration, for commands made with [ContextCmd] to return after their
+// context is cancelled on exit. The terminal is restored before waiting. By
+// default, Run doesn't wait for them.
+func WithShutdownTimeout(timeout time.Duration) ProgramOption {
+	return func(p *Program) {
+		p.shutdownTimeout = timeout
+	}
+}
+
+// WithKeySequences teaches the program the key sequences sent by a terminal
+// that Bubble Tea doesn't know, such as the function keys of older rxvt
+// versions or of a serial console:
+//
+//	p := tea.NewProgram(model, tea.WithKeySequences(map[string]tea.Key{
+//		"\x1bOq": {Type: tea.KeyEnd},
+//		"\x1bOw": {Type: tea.KeyHome},
+//	}))
+//
+// Each sequence prefixed with an escape character is recognized too, as the
+// key with Alt set. The sequences take precedence over the built-in ones, and
+// only apply to this program. The option can be given more than once.
+//
+// On Windows, the sequences are only recognized when the input isn't the
+// console, which reports keys rather than sequences.
+func WithKeySequences(seqs map[string]Key) ProgramOption {
+	return func(p *Program) {
+		if p.keySequences == nil {
+			p.keySequences = make(map[string]Key, len(seqs))
+		}
+		for seq, key := range seqs {
+			if seq != "" {
+				p.keySequences[seq] = key
+			}
+		}
+	}
+}
+
+// WithTerminfoKeys makes the program recognize the key sequences listed in
+// the terminfo entry of the terminal, named by the TERM environment variable
+// of the program, such as the function keys of the Linux console, screen or
+// rxvt. The entry is looked up in the usual places, see terminfo(5).
+//
+// The sequences take precedence over the built-in ones, and those given
+// with [WithKeySequences] over them.
+func WithTerminfoKeys() ProgramOption {
+	return func(p *Program) {
+		p.loadTerminfo = true
+	}
+}
+
+// WithEscapeTimeout makes the program wait, for up to the given duration,
+// for more input after an escape character that ends a read, before reporting
+// it as the Escape key. Input that arrives in time makes the escape
+// character part of it, as alt+key or an escape sequence.
+//
+// By default, an escape character is the Escape key unless the rest of the
+// sequence arrives in the same read, which may not be the case over slow
+// connections, such as SSH, where alt+key then shows up as Escape followed
+// by the key. With a timeout, pressing Escape followed by another key within
+// the timeout reads as alt+key, so it should be kept short; 50ms is a good
+// start.
+func WithEscapeTimeout(timeout time.Duration) ProgramOption {
+	return func(p *Program) {
+		p.escTimeout = timeout
+	}
+}
+
 // WithOutput sets the output which, by default, is stdout. In most cases you
 // won't need to use this.
 func WithOutput(output io.Writer) ProgramOption {
@@ -84,6 +153,37 @@ func WithoutCatchPanics() ProgramOption {
 	}
 }
 
+// WithPanicMsgs keeps the program running when a command or Update panics,
+// rather than killing it and restoring the terminal. The panic is sent to
+// the program as a [PanicMsg] instead, with its stack trace, and when Update
+// panics the model is left as it was before the call. A panic while handling
+// a PanicMsg is dropped.
+//
+// Panics in View and Init still kill the program, and panics aren't caught
+// at all with [WithoutCatchPanics].
+func WithPanicMsgs() ProgramOption {
+	return func(p *Program) {
+		p.startupOptions |= withPanicMsgs
+	}
+}
+
+// WithErrorMsgs keeps the program running on errors that don't have to end
+// it, sending them to the program as an [ErrorMsg] with their source
+// instead:
+//
+//   - errors getting the size of the terminal;
+//   - errors reading input, after which no more input is read;
+//   - errors writing to the terminal, which are otherwise ignored;
+//   - errors running a process with [Exec] without a callback, which are
+//     otherwise ignored.
+//
+// Fatal errors, such as panics, still end the program.
+func WithErrorMsgs() ProgramOption {
+	return func(p *Program) {
+		p.startupOptions |= withErrorMsgs
+	}
+}
+
 // WithoutSignals will ignore OS signals.
 // This is mainly useful for testing.
 func WithoutSignals() ProgramOption {
@@ -166,6 +266,67 @@ func WithMouseAllMotion() ProgramOption {
 	}
 }
 
+// WithRecorder records the session to w in the asciicast v2 format, which
+// can be played back with asciinema. Everything written to the terminal is
+// recorded along with the raw input and window size changes, with
+// timestamps.
+//
+// Setting the TEA_RECORD environment variable to a file path has the same
+// effect, which is handy for getting recordings of bug reports from users.
+//
+// Only the output of the standard renderer is recorded, not that of a custom
+// renderer set with [WithRenderer] nor that of processes run with [Exec].
+// Input from the Windows console can't be recorded either.
+func WithRecorder(w io.Writer) ProgramOption {
+	return func(p *Program) {
+		p.recorder = newRecorder(w)
+	}
+}
+
+// WithTrace writes a trace log of the program to w, for diagnosing lost
+// input or slow commands after the fact. The log has a JSON object per
+// line, for:
+//
+//   - every message the program handles, with its type, its Go syntax
+//     representation, how long Update and View took, in nanoseconds, and
+//     the IDs of the commands it spawned;
+//   - every command run, with its ID, how long it ran and the message it
+//     returned;
+//   - everything the renderer writes to the terminal, such as frames, with
+//     its size and how long writing it took.
+//
+// Setting the TEA_TRACE environment variable to a file path has the same
+// effect.
+//
+// Only the output of the standard renderer is traced, not that of a custom
+// renderer set with [WithRenderer].
+func WithTrace(w io.Writer) ProgramOption {
+	return func(p *Program) {
+		p.tracer = newTracer(w)
+	}
+}
+
+// WithReplay replays the given input events, each at its time relative to
+// the start of the program. Input events are parsed like input read from the
+// terminal, so the program sees the same messages it would see if the input
+// was typed. Use it with [LoadAsciicast] to reproduce a recorded session, or
+// to script a walkthrough of a program:
+//
+//	p := tea.NewProgram(model, tea.WithReplay([]tea.ReplayEvent{
+//		tea.ReplayResize(0, 80, 24),
+//		tea.ReplayKey(time.Second, tea.Key{Type: tea.KeyDown}),
+//		tea.ReplayPaste(2*time.Second, "hello"),
+//		tea.ReplayKey(3*time.Second, tea.Key{Type: tea.KeyEnter}),
+//	}))
+//
+// Input from the terminal is still read while replaying; use
+// [WithInput](nil) to ignore it.
+func WithReplay(events []ReplayEvent) ProgramOption {
+	return func(p *Program) {
+		p.replayEvents = events
+	}
+}
+
 // WithoutRenderer disables the renderer. When this is set output and log
 // statements will be plainly sent to stdout (or another output if one is set)
 // without any rendering and redrawing logic. In other words, printing and
@@ -180,6 +341,18 @@ func WithoutRenderer() ProgramOption {
 	}
 }
 
+// WithRenderer sets a custom renderer, replacing the standard one. This can be
+// used to render to something other than a terminal, such as a test recorder
+// or an HTML mirror. See [Renderer] for details.
+//
+// The renderer is responsible for writing its own output; the output set with
+// [WithOutput] is only used to configure the terminal.
+func WithRenderer(r Renderer) ProgramOption {
+	return func(p *Program) {
+		p.renderer = r
+	}
+}
+
 // WithANSICompressor removes redundant ANSI sequences to produce potentially
 // smaller output, at the cost of some processing overhead.
 //
@@ -194,11 +367,42 @@ func WithANSICompressor() ProgramOption {
 	}
 }
 
+// WithCellRenderer makes the renderer compare frames cell by cell rather than
+// line by line. Instead of rewriting every line that changed, only the cells
+// that changed are written, along with the cursor movements needed to reach
+// them. This can greatly reduce the amount of output for views where small
+// parts of long lines change often, such as a ticking clock in a wide table,
+// which matters on slow connections like SSH.
+//
+// Escape sequences in the view other than SGR styles and OSC 8 hyperlinks
+// are not preserved by this renderer.
+func WithCellRenderer() ProgramOption {
+	return func(p *Program) {
+		p.startupOptions |= withCellRenderer
+	}
+}
+
+// WithSynchronousCommands runs commands synchronously on the event loop,
+// one at a time and in order, rather than each in its own goroutine. The
+// messages they return are processed before any further input, which makes
+// programs deterministic, e.g. in tests.
+//
+// Commands that block, such as those waiting on a channel, block the whole
+// program in this mode, and commands that sleep, such as [Tick], delay it.
+func WithSynchronousCommands() ProgramOption {
+	return func(p *Program) {
+		p.startupOptions |= withSynchronousCommands
+	}
+}
+
 // WithFilter supplies an event filter that will be invoked before Bubble Tea
 // processes a tea.Msg. The event filter can return any tea.Msg which will then
 // get handled by Bubble Tea instead of the original event. If the event filter
 // returns nil, the event will be ignored and Bubble Tea will not process it.
 //
+// The filter is a [Middleware], run in order with those added with
+// [WithMiddleware].
+//
 // As an example, this could be used to prevent a program from shutting down if
 // there are unsaved changes.
 //
@@ -224,8 +428,39 @@ func WithANSICompressor() ProgramOption {
 //		os.Exit(1)
 //	}
 func WithFilter(filter func(Model, Msg) Msg) ProgramOption {
+	return WithMiddleware(func(next UpdateFunc) UpdateFunc {
+		return func(m Model, msg Msg) (Model, Cmd) {
+			if msg = filter(m, msg); msg == nil {
+				return m, nil
+			}
+			return next(m, msg)
+		}
+	})
+}
+
+// WithMiddleware adds middleware that wraps the update of the model. Each
+// middleware can inspect or replace messages before they're handled, observe
+// the resulting model and command, and short-circuit by not calling next.
+//
+// Middleware runs in the order it's added, the first one outermost, also
+// across multiple WithMiddleware and [WithFilter] options.
+func WithMiddleware(middleware ...Middleware) ProgramOption {
+	return func(p *Program) {
+		p.middleware = append(p.middleware, middleware...)
+	}
+}
+
+// WithWindowSize sets the size of the window when the program starts. The
+// program receives a [WindowSizeMsg] with this size, and the renderer lays
+// out frames for it, instead of the size of the terminal.
+//
+// This is mostly useful when the output isn't a terminal, e.g. a pipe, a
+// socket or a buffer in tests, in which case the program doesn't receive a
+// WindowSizeMsg otherwise. Use [Program.Resize] to change the size later.
+func WithWindowSize(width, height int) ProgramOption {
 	return func(p *Program) {
-		p.filter = filter
+		p.startupWidth = width
+		p.startupHeight = height
 	}
 }
 
@@ -238,6 +473,63 @@ func WithFPS(fps int) ProgramOption {
 	}
 }
 
+// WithKeyboardEnhancements enables the kitty keyboard protocol with the given
+// progressive enhancements. This allows keys that are ambiguous in the legacy
+// encoding to be told apart, such as ctrl+i and tab or shift+enter and
+// enter, and reports the full set of modifiers in [Key.Mod]. Escape codes
+// are always disambiguated when this option is used.
+//
+// Example:
+//
+//	p := tea.NewProgram(model, tea.WithKeyboardEnhancements(tea.ReportEventTypes))
+//
+// The enhancements are disabled when the program exits. Terminals that don't
+// support the kitty keyboard protocol will ignore this option and continue to
+// send legacy key sequences.
+func WithKeyboardEnhancements(enhancements KeyboardEnhancements) ProgramOption {
+	return func(p *Program) {
+		p.keyboardEnhancements = enhancements | DisambiguateEscapeCodes
+	}
+}
+
+// WithModifyOtherKeys enables xterm's modifyOtherKeys mode, at level 2. Like
+// the kitty keyboard protocol, see [WithKeyboardEnhancements], it allows keys
+// that are ambiguous in the legacy encoding to be told apart, such as ctrl+i
+// and tab, ctrl+enter and enter or ctrl+shift+a and ctrl+a, and reports the
+// modifiers in [Key.Mod]. It's supported by xterm and a few other terminals
+// that don't support the kitty keyboard protocol.
+//
+// The mode is disabled when the program exits. Terminals that don't support
+// it will ignore this option and continue to send legacy key sequences.
+func WithModifyOtherKeys() ProgramOption {
+	return func(p *Program) {
+		p.startupOptions |= withModifyOtherKeys
+	}
+}
+
+// WithSynchronizedOutput makes the renderer write every frame as a
+// synchronized update (mode 2026), so that the terminal doesn't show frames
+// half drawn, which looks like tearing on views that update quickly.
+//
+// By default, synchronized output is used when the terminal reports
+// supporting it. This option forces it on, e.g. for terminals that support
+// it but don't answer the query. Terminals that don't support it ignore it.
+func WithSynchronizedOutput() ProgramOption {
+	return func(p *Program) {
+		p.startupOptions |= withSynchronizedOutput
+		p.startupOptions &^= withoutSynchronizedOutput
+	}
+}
+
+// WithoutSynchronizedOutput disables synchronized output, even when the
+// terminal supports it. See [WithSynchronizedOutput].
+func WithoutSynchronizedOutput() ProgramOption {
+	return func(p *Program) {
+		p.startupOptions |= withoutSynchronizedOutput
+		p.startupOptions &^= withSynchronizedOutput
+	}
+}
+
 // WithReportFocus enables reporting when the terminal gains and loses
 // focus. When this is enabled [FocusMsg] and [BlurMsg] messages will be sent
 // to your Update method.
@@ -6,6 +6,7 @@ import (
 	"os"
 	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestOptions(t *testing.T) {
@@ -38,6 +39,14 @@ func TestOptions(t *testing.T) {
 		}
 	})
 
+	t.Run("custom renderer", func(t *testing.T) {
+		r := &nilRenderer{}
+		p := NewProgram(nil, WithRenderer(r))
+		if p.renderer != r {
+			t.Errorf("expected renderer to be %v, got %v", r, p.renderer)
+		}
+	})
+
 	t.Run("without signals", func(t *testing.T) {
 		p := NewProgram(nil, WithoutSignals())
 		if atomic.LoadUint32(&p.ignoreSignals) == 0 {
@@ -47,11 +56,32 @@ func TestOptions(t *testing.T) {
 
 	t.Run("filter", func(t *testing.T) {
 		p := NewProgram(nil, WithFilter(func(_ Model, msg Msg) Msg { return msg }))
-		if p.filter == nil {
+		if len(p.middleware) != 1 {
 			t.Errorf("expected filter to be set")
 		}
 	})
 
+	t.Run("keyboard enhancements", func(t *testing.T) {
+		p := NewProgram(nil, WithKeyboardEnhancements(ReportEventTypes))
+		if want := DisambiguateEscapeCodes | ReportEventTypes; p.keyboardEnhancements != want {
+			t.Errorf("expected keyboard enhancements %v, got %v", want, p.keyboardEnhancements)
+		}
+	})
+
+	t.Run("window size", func(t *testing.T) {
+		p := NewProgram(nil, WithWindowSize(100, 30))
+		if p.startupWidth != 100 || p.startupHeight != 30 {
+			t.Errorf("expected window size 100x30, got %dx%d", p.startupWidth, p.startupHeight)
+		}
+	})
+
+	t.Run("escape timeout", func(t *testing.T) {
+		p := NewProgram(nil, WithEscapeTimeout(50*time.Millisecond))
+		if p.escTimeout != 50*time.Millisecond {
+			t.Errorf("expected escape timeout 50ms, got %v", p.escTimeout)
+		}
+	})
+
 	t.Run("external context", func(t *testing.T) {
 		extCtx, extCancel := context.WithCancel(context.Background())
 		defer extCancel()
@@ -104,10 +134,40 @@ func TestOptions(t *testing.T) {
 			exercise(t, WithoutCatchPanics(), withoutCatchPanics)
 		})
 
+		t.Run("panic messages", func(t *testing.T) {
+			exercise(t, WithPanicMsgs(), withPanicMsgs)
+		})
+
+		t.Run("error messages", func(t *testing.T) {
+			exercise(t, WithErrorMsgs(), withErrorMsgs)
+		})
+
+		t.Run("modify other keys", func(t *testing.T) {
+			exercise(t, WithModifyOtherKeys(), withModifyOtherKeys)
+		})
+
 		t.Run("without signal handler", func(t *testing.T) {
 			exercise(t, WithoutSignalHandler(), withoutSignalHandler)
 		})
 
+		t.Run("cell renderer", func(t *testing.T) {
+			exercise(t, WithCellRenderer(), withCellRenderer)
+		})
+
+		t.Run("synchronous commands", func(t *testing.T) {
+			exercise(t, WithSynchronousCommands(), withSynchronousCommands)
+		})
+
+		t.Run("synchronized output", func(t *testing.T) {
+			exercise(t, WithSynchronizedOutput(), withSynchronizedOutput)
+			exercise(t, WithoutSynchronizedOutput(), withoutSynchronizedOutput)
+
+			p := NewProgram(nil, WithoutSynchronizedOutput(), WithSynchronizedOutput())
+			if p.startupOptions.has(withoutSynchronizedOutput) {
+				t.Errorf("expected the last synchronized output option to win, got %v", p.startupOptions)
+			}
+		})
+
 		t.Run("mouse cell motion", func(t *testing.T) {
 			p := NewProgram(nil, WithMouseAllMotion(), WithMouseCellMotion())
 			if !p.startupOptions.has(withMouseCellMotion) {
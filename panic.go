@@ -0,0 +1,73 @@
+package tea
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicMsg is sent when a command or Update panics, with [WithPanicMsgs].
+type PanicMsg struct {
+	// Value is the value the panic was called with.
+	Value any
+
+	// Stack is the stack trace of the goroutine that panicked.
+	Stack []byte
+}
+
+// String returns the value of the panic.
+func (m PanicMsg) String() string {
+	return fmt.Sprint(m.Value)
+}
+
+// panicMsgs reports whether panics are turned into PanicMsgs.
+func (p *Program) panicMsgs() bool {
+	return p.startupOptions.has(withPanicMsgs) && !p.startupOptions.has(withoutCatchPanics)
+}
+
+// catchCmdPanic recovers from a panic in the goroutine of a command, sending
+// a PanicMsg to the program or killing it, unless panics aren't caught. It has
+// to be deferred itself to recover.
+func (p *Program) catchCmdPanic() {
+	if p.startupOptions.has(withoutCatchPanics) {
+		return
+	}
+	r := recover()
+	if r == nil {
+		return
+	}
+	if p.panicMsgs() {
+		p.Send(PanicMsg{Value: r, Stack: debug.Stack()})
+		return
+	}
+	p.recoverFromGoPanic(r)
+}
+
+// runCmd runs a command on the event loop, returning a PanicMsg if it panics
+// and panics are turned into PanicMsgs.
+func (p *Program) runCmd(cmd Cmd) (msg Msg) {
+	if p.panicMsgs() {
+		defer func() {
+			if r := recover(); r != nil {
+				msg = PanicMsg{Value: r, Stack: debug.Stack()}
+			}
+		}()
+	}
+	return cmd()
+}
+
+// recoverUpdate returns an UpdateFunc that calls update, and if it panics,
+// keeps the model and reports the panic to the program as a PanicMsg with
+// report. Panics handling a PanicMsg aren't reported, so as not to loop.
+func recoverUpdate(update UpdateFunc, report func(Msg)) UpdateFunc {
+	return func(model Model, msg Msg) (newModel Model, cmd Cmd) {
+		defer func() {
+			if r := recover(); r != nil {
+				newModel, cmd = model, nil
+				if _, ok := msg.(PanicMsg); !ok {
+					report(PanicMsg{Value: r, Stack: debug.Stack()})
+				}
+			}
+		}()
+		return update(model, msg)
+	}
+}
@@ -0,0 +1,93 @@
+package tea
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+type panicModel struct {
+	init   Cmd
+	n      int
+	panics []PanicMsg
+}
+
+func (m panicModel) Init() Cmd {
+	return m.init
+}
+
+func (m panicModel) Update(msg Msg) (Model, Cmd) {
+	switch msg := msg.(type) {
+	case PanicMsg:
+		m.panics = append(m.panics, msg)
+		return m, Quit
+	case string:
+		m.n++
+		if msg == "boom" {
+			panic("update")
+		}
+	}
+	return m, nil
+}
+
+func (m panicModel) View() string {
+	return ""
+}
+
+func TestPanicMsgs(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		init  Cmd
+		value string
+		n     int
+	}{
+		{"cmd", func() Msg { panic("cmd") }, "cmd", 0},
+		{"context cmd", ContextCmd(func(_ context.Context) Msg { panic("context cmd") }), "context cmd", 0},
+		{"sequence cmd", Sequence(
+			func() Msg { return nil },
+			func() Msg { panic("sequence cmd") },
+		), "sequence cmd", 0},
+		{"sequence batch cmd", Sequence(Batch(
+			func() Msg { return nil },
+			func() Msg { panic("sequence batch cmd") },
+		)), "sequence batch cmd", 0},
+		{"update", Sequence(
+			func() Msg { return "ok" },
+			func() Msg { return "boom" },
+		), "update", 1},
+	} {
+		for _, synchronous := range []bool{false, true} {
+			name := tc.name
+			if synchronous {
+				name += " sync"
+			}
+
+			t.Run(name, func(t *testing.T) {
+				var buf, in bytes.Buffer
+				opts := []ProgramOption{WithInput(&in), WithOutput(&buf), WithPanicMsgs()}
+				if synchronous {
+					opts = append(opts, WithSynchronousCommands())
+				}
+				m, err := NewProgram(panicModel{init: tc.init}, opts...).Run()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				got := m.(panicModel)
+				if len(got.panics) == 0 {
+					t.Fatal("expected a panic message")
+				}
+				if got.panics[0].Value != tc.value {
+					t.Errorf("expected panic %q, got %v", tc.value, got.panics[0].Value)
+				}
+				if !strings.Contains(string(got.panics[0].Stack), "panic_test.go") {
+					t.Errorf("expected the stack trace of the panic, got %s", got.panics[0].Stack)
+				}
+				if got.n != tc.n {
+					t.Errorf("expected the model before the panic, got %d updates", got.n)
+				}
+			})
+		}
+	}
+}
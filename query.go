@@ -0,0 +1,350 @@
+package tea
+
+import (
+	"bytes"
+	"encoding/hex"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// PrimaryDeviceAttributesMsg is sent with the primary device attributes
+// (DA1) the terminal reports in reply to [RequestPrimaryDeviceAttributes].
+// The first attribute is the conformance level, and the rest are the
+// features the terminal supports, e.g. 4 for sixel graphics and 22 for ANSI
+// color.
+type PrimaryDeviceAttributesMsg []int
+
+// TerminalVersionMsg is sent with the name and version the terminal reports
+// in reply to [RequestTerminalVersion], e.g. "kitty(0.35.2)" or
+// "XTerm(390)".
+type TerminalVersionMsg string
+
+// String returns the name and version of the terminal.
+func (m TerminalVersionMsg) String() string {
+	return string(m)
+}
+
+// ModeReportMsg is sent with the setting of a DEC private mode the terminal
+// reports in reply to [RequestMode].
+type ModeReportMsg struct {
+	// Mode is the number of the mode, e.g. 2026 for synchronized output.
+	Mode int
+
+	// Setting is the setting of the mode. Terminals that don't know about
+	// the mode report it as not recognized.
+	Setting ansi.ModeSetting
+}
+
+// CapabilityMsg is sent with a terminfo capability the terminal reports in
+// reply to [RequestCapability].
+type CapabilityMsg struct {
+	// Name is the name of the capability, e.g. "Tc" or "colors".
+	Name string
+
+	// Value is the value of the capability. It's empty for boolean
+	// capabilities.
+	Value string
+
+	// Supported reports whether the terminal knows the capability.
+	Supported bool
+}
+
+// QueryResultMsg is sent by [QueryTerminal] with the replies to its queries.
+type QueryResultMsg struct {
+	// Replies are the replies, in the order the terminal sent them. They
+	// include the primary device attributes, which are requested last to
+	// know when the terminal has answered all other queries.
+	Replies []Msg
+
+	// TimedOut reports whether the terminal didn't answer in time, in which
+	// case some replies might be missing.
+	TimedOut bool
+}
+
+// queryMsg is an internal message used to send a query to the terminal.
+type queryMsg struct {
+	seq string
+
+	// reply identifies the reply to the query, see queryReply.
+	reply string
+}
+
+// queryReply returns what a reply to a query identifies as, for
+// [QueryTerminal] to only collect the replies it asked for, or "" if the
+// message isn't a reply.
+func queryReply(msg Msg) string {
+	switch msg := msg.(type) {
+	case TerminalVersionMsg:
+		return "version"
+	case ModeReportMsg:
+		return "mode " + strconv.Itoa(msg.Mode)
+	case CapabilityMsg:
+		return "capability " + msg.Name
+	}
+	return ""
+}
+
+// RequestPrimaryDeviceAttributes produces a command that asks the terminal
+// for its primary device attributes. The reply is sent to the program as a
+// [PrimaryDeviceAttributesMsg]. Virtually all terminals reply to this query.
+func RequestPrimaryDeviceAttributes() Cmd {
+	return func() Msg {
+		return queryMsg{seq: ansi.RequestPrimaryDeviceAttributes}
+	}
+}
+
+// RequestTerminalVersion produces a command that asks the terminal for its
+// name and version (XTVERSION). The reply is sent to the program as a
+// [TerminalVersionMsg].
+func RequestTerminalVersion() Cmd {
+	return func() Msg {
+		return queryMsg{
+			seq:   ansi.RequestNameVersion,
+			reply: queryReply(TerminalVersionMsg("")),
+		}
+	}
+}
+
+// RequestMode produces a command that asks the terminal for the setting of
+// a DEC private mode (DECRQM), e.g. 2026 for synchronized output. The reply
+// is sent to the program as a [ModeReportMsg].
+func RequestMode(mode int) Cmd {
+	return func() Msg {
+		return queryMsg{
+			seq:   ansi.RequestMode(ansi.DECMode(mode)),
+			reply: queryReply(ModeReportMsg{Mode: mode}),
+		}
+	}
+}
+
+// RequestCapability produces a command that asks the terminal for the value
+// of a terminfo capability (XTGETTCAP), e.g. "Tc" or "colors". The reply is
+// sent to the program as a [CapabilityMsg].
+func RequestCapability(name string) Cmd {
+	return func() Msg {
+		return queryMsg{
+			seq:   ansi.XTGETTCAP(name),
+			reply: queryReply(CapabilityMsg{Name: name}),
+		}
+	}
+}
+
+// terminalQueryMsg is an internal message used to send queries to the
+// terminal and collect their replies.
+type terminalQueryMsg struct {
+	seq     string
+	replies []string
+	timeout time.Duration
+}
+
+// defaultQueryTimeout is the timeout of terminal queries given none.
+const defaultQueryTimeout = time.Second
+
+// QueryTerminal produces a command that sends the given queries to the
+// terminal and collects the replies into a single [QueryResultMsg], rather
+// than sending them to the program one by one. The queries are commands
+// such as [RequestTerminalVersion] and [RequestMode].
+//
+// Terminals ignore queries they don't understand, so the primary device
+// attributes, which virtually all terminals report, are requested last:
+// once they arrive all other replies have arrived too. If the terminal
+// doesn't answer within the timeout, the replies received so far are sent
+// with TimedOut set; a timeout of zero or less stands for one second. Only
+// the replies to the given queries are collected, other replies are sent to
+// the program as usual. The program keeps running in the meantime, so it's
+// safe to query the terminal on startup:
+//
+//	func (m model) Init() tea.Cmd {
+//		return tea.QueryTerminal(time.Second,
+//			tea.RequestTerminalVersion(),
+//			tea.RequestMode(2026),
+//		)
+//	}
+func QueryTerminal(timeout time.Duration, queries ...Cmd) Cmd {
+	if timeout <= 0 {
+		timeout = defaultQueryTimeout
+	}
+	return func() Msg {
+		var seq strings.Builder
+		var replies []string
+		for _, query := range queries {
+			if query == nil {
+				continue
+			}
+			if msg, ok := query().(queryMsg); ok {
+				seq.WriteString(msg.seq)
+				if msg.reply != "" {
+					replies = append(replies, msg.reply)
+				}
+			}
+		}
+		if !strings.HasSuffix(seq.String(), ansi.RequestPrimaryDeviceAttributes) {
+			seq.WriteString(ansi.RequestPrimaryDeviceAttributes)
+		}
+		return terminalQueryMsg{seq: seq.String(), replies: replies, timeout: timeout}
+	}
+}
+
+// pendingQuery is a terminal query waiting for its replies.
+type pendingQuery struct {
+	replies []Msg
+
+	// expected are the replies still expected, see queryReply.
+	expected []string
+}
+
+// queryTimeoutMsg is sent when a terminal query times out.
+type queryTimeoutMsg struct {
+	query *pendingQuery
+}
+
+// startQuery sends the queries to the terminal and waits for the replies.
+func (p *Program) startQuery(msg terminalQueryMsg) {
+	q := &pendingQuery{expected: msg.replies}
+	p.queries = append(p.queries, q)
+	p.execute(msg.seq)
+
+	time.AfterFunc(msg.timeout, func() {
+		p.Send(queryTimeoutMsg{query: q})
+	})
+}
+
+// collectQueryReply collects the replies to pending terminal queries. It
+// returns the message to process in place of msg, which is nil if the
+// message was collected.
+func (p *Program) collectQueryReply(msg Msg) Msg {
+	if timeout, ok := msg.(queryTimeoutMsg); ok {
+		for i, q := range p.queries {
+			if q == timeout.query {
+				p.queries = append(p.queries[:i], p.queries[i+1:]...)
+				return QueryResultMsg{Replies: q.replies, TimedOut: true}
+			}
+		}
+		// The terminal answered in time.
+		return nil
+	}
+	if len(p.queries) == 0 {
+		return msg
+	}
+
+	// Terminals answer queries in order, so the primary device attributes
+	// end the oldest query, and the other replies are for the oldest query
+	// that asked for them.
+	if _, ok := msg.(PrimaryDeviceAttributesMsg); ok {
+		q := p.queries[0]
+		q.replies = append(q.replies, msg)
+		p.queries = p.queries[1:]
+		return QueryResultMsg{Replies: q.replies}
+	}
+	reply := queryReply(msg)
+	if reply == "" {
+		return msg
+	}
+	for _, q := range p.queries {
+		if i := slices.Index(q.expected, reply); i != -1 {
+			q.expected = slices.Delete(q.expected, i, i+1)
+			q.replies = append(q.replies, msg)
+			return nil
+		}
+	}
+	return msg
+}
+
+// parseQueryReply parses the reply to a query sent as a control sequence.
+func parseQueryReply(seq []byte) (Msg, bool) {
+	// Only replies to DEC queries are handled.
+	if len(seq) < 4 || seq[2] != '?' { //nolint:mnd
+		return nil, false
+	}
+	params := seq[3 : len(seq)-1]
+
+	switch {
+	case seq[len(seq)-1] == 'c':
+		// DA1: CSI ? Ps ; ... c
+		var attrs PrimaryDeviceAttributesMsg
+		for _, p := range bytes.Split(params, []byte(";")) {
+			n, err := strconv.Atoi(string(p))
+			if err != nil {
+				return nil, false
+			}
+			attrs = append(attrs, n)
+		}
+		return attrs, true
+
+	case bytes.HasSuffix(params, []byte("$")) && seq[len(seq)-1] == 'y':
+		// DECRPM: CSI ? Pd ; Ps $ y
+		mode, setting, ok := bytes.Cut(params[:len(params)-1], []byte(";"))
+		if !ok {
+			return nil, false
+		}
+		m, err1 := strconv.Atoi(string(mode))
+		s, err2 := strconv.Atoi(string(setting))
+		if err1 != nil || err2 != nil {
+			return nil, false
+		}
+		return ModeReportMsg{Mode: m, Setting: ansi.ModeSetting(s)}, true //nolint:gosec
+	}
+	return nil, false
+}
+
+// detectDCSReply detects the reply to a query sent as a device control
+// string, terminated with ST:
+//
+//	DCS > | text ST      (XTVERSION)
+//	DCS 1 + r Pt ST      (XTGETTCAP)
+//	DCS 0 + r Pt ST      (XTGETTCAP, unknown capability)
+func detectDCSReply(input []byte) (hasReply bool, width int, msg Msg) {
+	var prefix string
+	for _, p := range []string{"\x1bP>|", "\x1bP1+r", "\x1bP0+r"} {
+		if bytes.HasPrefix(input, []byte(p)) {
+			prefix = p
+			break
+		}
+	}
+	if prefix == "" {
+		return false, 0, nil
+	}
+
+	data, width, ok := cutStringTerminator(input[len(prefix):])
+	if !ok {
+		// The reply continues beyond the input buffer. Tell the outer loop
+		// we have done a short read and we want more.
+		return true, 0, nil
+	}
+	width += len(prefix)
+
+	if prefix == "\x1bP>|" {
+		return true, width, TerminalVersionMsg(data)
+	}
+
+	// Capabilities are encoded as hex, with the value following the name
+	// after an equal sign.
+	capability := CapabilityMsg{Supported: prefix == "\x1bP1+r"}
+	pair, _, _ := bytes.Cut(data, []byte(";"))
+	name, value, _ := bytes.Cut(pair, []byte("="))
+	n, err1 := hex.DecodeString(string(name))
+	v, err2 := hex.DecodeString(string(value))
+	if err1 != nil || err2 != nil {
+		return true, width, unknownDCSSequenceMsg(input[:width])
+	}
+	capability.Name, capability.Value = string(n), string(v)
+	return true, width, capability
+}
+
+// cutStringTerminator returns the data of a control string up to its
+// terminator, either BEL or ST, and the length of the data including the
+// terminator. It reports whether it found the terminator.
+func cutStringTerminator(input []byte) (data []byte, width int, ok bool) {
+	end, termLen := bytes.IndexByte(input, '\a'), 1
+	if st := bytes.Index(input, []byte("\x1b\\")); st != -1 && (end == -1 || st < end) {
+		end, termLen = st, 2 //nolint:mnd
+	}
+	if end == -1 {
+		return nil, 0, false
+	}
+	return input[:end], end + termLen, true
+}
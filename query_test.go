@@ -0,0 +1,202 @@
+package tea
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestDetectQueryReplies(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		msg   Msg
+	}{
+		{"da1", "\x1b[?62;4;22c", PrimaryDeviceAttributesMsg{62, 4, 22}},
+		{"decrpm", "\x1b[?2026;2$y", ModeReportMsg{Mode: 2026, Setting: ansi.ModeReset}},
+		{"decrpm unknown", "\x1b[?2027;0$y", ModeReportMsg{Mode: 2027, Setting: ansi.ModeNotRecognized}},
+		{"xtversion", "\x1bP>|kitty(0.35.2)\x1b\\", TerminalVersionMsg("kitty(0.35.2)")},
+		{"xtgettcap", "\x1bP1+r636F6C6F7273=323536\x1b\\", CapabilityMsg{Name: "colors", Value: "256", Supported: true}},
+		{"xtgettcap boolean", "\x1bP1+r5463\x1b\\", CapabilityMsg{Name: "Tc", Supported: true}},
+		{"xtgettcap unknown", "\x1bP0+r5463\x1b\\", CapabilityMsg{Name: "Tc"}},
+		{"xtgettcap unknown without name", "\x1bP0+r\x1b\\", CapabilityMsg{}},
+		{"xtgettcap invalid", "\x1bP1+rzz\x1b\\", unknownDCSSequenceMsg("\x1bP1+rzz\x1b\\")},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w, msg := detectOneMsg([]byte(tc.input+"a"), false)
+			if w != len(tc.input) {
+				t.Errorf("expected width %d, got %d", len(tc.input), w)
+			}
+			if !reflect.DeepEqual(msg, tc.msg) {
+				t.Errorf("expected %#v, got %#v", tc.msg, msg)
+			}
+		})
+	}
+
+	// Incomplete replies wait for more input.
+	if w, _ := detectOneMsg([]byte("\x1bP>|kitty"), false); w != 0 {
+		t.Errorf("expected an incomplete reply to wait for more input, got width %d", w)
+	}
+}
+
+type queryModel struct {
+	mtx     sync.Mutex
+	query   Cmd
+	keys    []string
+	replies []Msg
+	results []QueryResultMsg
+}
+
+func (m *queryModel) Init() Cmd {
+	return m.query
+}
+
+func (m *queryModel) Update(msg Msg) (Model, Cmd) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	switch msg := msg.(type) {
+	case KeyMsg:
+		m.keys = append(m.keys, msg.String())
+	case TerminalVersionMsg, ModeReportMsg, CapabilityMsg:
+		m.replies = append(m.replies, msg)
+	case QueryResultMsg:
+		m.results = append(m.results, msg)
+		return m, Quit
+	}
+	return m, nil
+}
+
+func (m *queryModel) View() string {
+	return ""
+}
+
+// queryWriter is an output that reports when the primary device attributes
+// have been requested, so replies aren't sent before the queries.
+type queryWriter struct {
+	mtx  sync.Mutex
+	buf  bytes.Buffer
+	sent chan struct{}
+}
+
+func (w *queryWriter) Write(p []byte) (int, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if bytes.Contains(p, []byte(ansi.RequestPrimaryDeviceAttributes)) {
+		close(w.sent)
+	}
+	return w.buf.Write(p)
+}
+
+func (w *queryWriter) String() string {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return w.buf.String()
+}
+
+func TestQueryTerminal(t *testing.T) {
+	t.Run("replies", func(t *testing.T) {
+		in, w := io.Pipe()
+		out := &queryWriter{sent: make(chan struct{})}
+		m := &queryModel{query: QueryTerminal(time.Minute,
+			RequestTerminalVersion(),
+			RequestMode(2026),
+			RequestCapability("Tc"),
+		)}
+		p := NewProgram(m, WithInput(in), WithOutput(out))
+		go func() {
+			_, _ = io.WriteString(w, "a")
+			<-out.sent
+			_, _ = io.WriteString(w, "\x1bP>|XTerm(390)\x1b\\")
+			_, _ = io.WriteString(w, "\x1b[?2026;1$y")
+			_, _ = io.WriteString(w, "\x1bP0+r5463\x1b\\")
+			_, _ = io.WriteString(w, "\x1b[?62;22c")
+		}()
+		if _, err := p.Run(); err != nil {
+			t.Fatal(err)
+		}
+		_ = w.Close()
+
+		if !strings.Contains(out.String(), "\x1b[>q\x1b[?2026$p\x1bP+q5463\x1b\\\x1b[c") {
+			t.Errorf("expected the queries in the output, got %q", out.String())
+		}
+
+		m.mtx.Lock()
+		defer m.mtx.Unlock()
+		if len(m.keys) != 1 || m.keys[0] != "a" {
+			t.Errorf("expected only the key press to reach the model, got %q", m.keys)
+		}
+		want := QueryResultMsg{Replies: []Msg{
+			TerminalVersionMsg("XTerm(390)"),
+			ModeReportMsg{Mode: 2026, Setting: ansi.ModeSet},
+			CapabilityMsg{Name: "Tc"},
+			PrimaryDeviceAttributesMsg{62, 22},
+		}}
+		if len(m.results) != 1 || !reflect.DeepEqual(m.results[0], want) {
+			t.Errorf("expected %#v, got %#v", want, m.results)
+		}
+	})
+
+	t.Run("other replies", func(t *testing.T) {
+		in, w := io.Pipe()
+		out := &queryWriter{sent: make(chan struct{})}
+		m := &queryModel{query: QueryTerminal(time.Minute, RequestMode(2026))}
+		p := NewProgram(m, WithInput(in), WithOutput(out))
+		go func() {
+			<-out.sent
+			_, _ = io.WriteString(w, "\x1bP>|XTerm(390)\x1b\\")
+			_, _ = io.WriteString(w, "\x1b[?2004;2$y")
+			_, _ = io.WriteString(w, "\x1b[?2026;1$y")
+			_, _ = io.WriteString(w, "\x1b[?62;22c")
+		}()
+		if _, err := p.Run(); err != nil {
+			t.Fatal(err)
+		}
+		_ = w.Close()
+
+		m.mtx.Lock()
+		defer m.mtx.Unlock()
+		wantReplies := []Msg{
+			TerminalVersionMsg("XTerm(390)"),
+			ModeReportMsg{Mode: 2004, Setting: ansi.ModeReset},
+		}
+		if !reflect.DeepEqual(m.replies, wantReplies) {
+			t.Errorf("expected the replies not asked for to reach the model, got %#v", m.replies)
+		}
+		want := QueryResultMsg{Replies: []Msg{
+			ModeReportMsg{Mode: 2026, Setting: ansi.ModeSet},
+			PrimaryDeviceAttributesMsg{62, 22},
+		}}
+		if len(m.results) != 1 || !reflect.DeepEqual(m.results[0], want) {
+			t.Errorf("expected %#v, got %#v", want, m.results)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		var buf bytes.Buffer
+		m := &queryModel{query: QueryTerminal(10*time.Millisecond, RequestTerminalVersion())}
+		p := NewProgram(m, WithInput(nil), WithOutput(&buf))
+		if _, err := p.Run(); err != nil {
+			t.Fatal(err)
+		}
+
+		m.mtx.Lock()
+		defer m.mtx.Unlock()
+		if len(m.results) != 1 || !m.results[0].TimedOut || len(m.results[0].Replies) != 0 {
+			t.Errorf("expected the query to time out, got %#v", m.results)
+		}
+	})
+	t.Run("no timeout", func(t *testing.T) {
+		msg := QueryTerminal(0, RequestTerminalVersion())()
+		if q, ok := msg.(terminalQueryMsg); !ok || q.timeout != defaultQueryTimeout {
+			t.Errorf("expected the default timeout, got %#v", msg)
+		}
+	})
+}
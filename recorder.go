@@ -0,0 +1,191 @@
+package tea
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/muesli/cancelreader"
+)
+
+// recordEnv is the environment variable that makes a program record its
+// session to the given file, as if set with [WithRecorder].
+const recordEnv = "TEA_RECORD"
+
+// Default size of recordings when the size of the terminal is unknown.
+const (
+	defaultRecordWidth  = 80
+	defaultRecordHeight = 24
+)
+
+// Event types of asciicast v2 recordings.
+const (
+	recordOutput = "o"
+	recordInput  = "i"
+	recordResize = "r"
+)
+
+// recorder records a session in the asciicast v2 format.
+//
+// See https://docs.asciinema.org/manual/asciicast/v2/
+type recorder struct {
+	mtx   sync.Mutex
+	w     io.Writer
+	start time.Time
+
+	// width and height are the last recorded terminal size.
+	width, height int
+
+	// partial holds the incomplete UTF-8 characters at the end of the last
+	// output and input data, which are recorded with the next data.
+	partial map[string][]byte
+
+	// err is the first write error, after which recording stops.
+	err error
+}
+
+func newRecorder(w io.Writer) *recorder {
+	return &recorder{w: w, partial: map[string][]byte{}}
+}
+
+// asciicastHeader is the header of an asciicast v2 recording.
+type asciicastHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// begin writes the header of the recording and starts the clock.
+func (r *recorder) begin(width, height int, environ []string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if width <= 0 || height <= 0 {
+		width, height = defaultRecordWidth, defaultRecordHeight
+	}
+	r.start = time.Now()
+	r.width, r.height = width, height
+
+	h := asciicastHeader{
+		Version:   2, //nolint:mnd
+		Width:     width,
+		Height:    height,
+		Timestamp: r.start.Unix(),
+	}
+	for _, kv := range environ {
+		k, v, _ := strings.Cut(kv, "=")
+		if k == "TERM" || k == "SHELL" {
+			if h.Env == nil {
+				h.Env = map[string]string{}
+			}
+			h.Env[k] = v
+		}
+	}
+
+	b, err := json.Marshal(h)
+	if err != nil {
+		r.err = err
+		return
+	}
+	r.write(append(b, '\n'))
+}
+
+// output records data written to the terminal.
+func (r *recorder) output(data []byte) {
+	r.record(recordOutput, data)
+}
+
+// input records data read from the terminal.
+func (r *recorder) input(data []byte) {
+	r.record(recordInput, data)
+}
+
+// resize records a change of the terminal size.
+func (r *recorder) resize(width, height int) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if width == r.width && height == r.height {
+		return
+	}
+	r.width, r.height = width, height
+	r.event(recordResize, strconv.Itoa(width)+"x"+strconv.Itoa(height))
+}
+
+func (r *recorder) record(kind string, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if p := r.partial[kind]; len(p) > 0 {
+		data = append(p, data...)
+		r.partial[kind] = nil
+	}
+
+	// Keep an incomplete character at the end for the next event, so it
+	// doesn't get mangled when encoded as JSON.
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if !utf8.RuneStart(data[i]) {
+			continue
+		}
+		if !utf8.FullRune(data[i:]) {
+			r.partial[kind] = append([]byte(nil), data[i:]...)
+			data = data[:i]
+		}
+		break
+	}
+
+	if len(data) > 0 {
+		r.event(kind, string(data))
+	}
+}
+
+// event writes an event line. It must be called with the lock held.
+func (r *recorder) event(kind, data string) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		r.err = err
+		return
+	}
+	elapsed := strconv.FormatFloat(time.Since(r.start).Seconds(), 'f', 6, 64) //nolint:mnd
+	r.write([]byte("[" + elapsed + `, "` + kind + `", ` + string(b) + "]\n"))
+}
+
+func (r *recorder) write(b []byte) {
+	if r.err != nil {
+		return
+	}
+	_, r.err = r.w.Write(b)
+}
+
+// recordingWriter records everything written to the terminal.
+type recordingWriter struct {
+	w   io.Writer
+	rec *recorder
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.rec.output(p[:n])
+	return n, err //nolint:wrapcheck
+}
+
+// recordingReader records everything read from the terminal.
+type recordingReader struct {
+	cancelreader.CancelReader
+	rec *recorder
+}
+
+func (r *recordingReader) Read(p []byte) (int, error) {
+	n, err := r.CancelReader.Read(p)
+	r.rec.input(p[:n])
+	return n, err //nolint:wrapcheck
+}
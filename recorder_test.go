@@ -0,0 +1,118 @@
+package tea
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// parseAsciicast parses a recording into its header and events.
+func parseAsciicast(t *testing.T, data string) (asciicastHeader, [][]any) {
+	t.Helper()
+
+	lines := strings.Split(strings.TrimSuffix(data, "\n"), "\n")
+	var header asciicastHeader
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+		t.Fatalf("invalid header %q: %v", lines[0], err)
+	}
+
+	var events [][]any
+	for _, line := range lines[1:] {
+		var ev []any
+		if err := json.Unmarshal([]byte(line), &ev); err != nil || len(ev) != 3 {
+			t.Fatalf("invalid event %q: %v", line, err)
+		}
+		events = append(events, ev)
+	}
+	return header, events
+}
+
+func TestRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRecorder(&buf)
+	r.begin(100, 30, []string{"TERM=xterm-256color", "HOME=/root"})
+
+	wide := []byte("世")
+	r.output([]byte("a"))
+	r.output(wide[:1])
+	r.output(wide[1:])
+	r.input([]byte("q"))
+	r.resize(100, 30) // unchanged
+	r.resize(120, 40)
+
+	header, events := parseAsciicast(t, buf.String())
+	if header.Version != 2 || header.Width != 100 || header.Height != 30 {
+		t.Errorf("unexpected header %+v", header)
+	}
+	if len(header.Env) != 1 || header.Env["TERM"] != "xterm-256color" {
+		t.Errorf("expected only TERM in the header environment, got %v", header.Env)
+	}
+
+	var got []string
+	for _, ev := range events {
+		got = append(got, ev[1].(string)+":"+ev[2].(string))
+	}
+	if want := []string{"o:a", "o:世", "i:q", "r:120x40"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected events %q, got %q", want, got)
+	}
+}
+
+func TestProgramRecording(t *testing.T) {
+	run := func(t *testing.T, opts ...ProgramOption) string {
+		t.Helper()
+
+		var buf bytes.Buffer
+		in := bytes.NewBufferString("q")
+
+		p := NewProgram(&testModel{}, append(opts, WithInput(in), WithOutput(&buf))...)
+		if _, err := p.Run(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	check := func(t *testing.T, output, recording string) {
+		t.Helper()
+
+		header, events := parseAsciicast(t, recording)
+		if header.Width != 80 || header.Height != 24 {
+			t.Errorf("expected the default size, got %dx%d", header.Width, header.Height)
+		}
+
+		var out, in strings.Builder
+		for _, ev := range events {
+			switch ev[1] {
+			case "o":
+				out.WriteString(ev[2].(string))
+			case "i":
+				in.WriteString(ev[2].(string))
+			}
+		}
+		if out.String() != output {
+			t.Errorf("expected recorded output %q, got %q", output, out.String())
+		}
+		if in.String() != "q" {
+			t.Errorf("expected recorded input %q, got %q", "q", in.String())
+		}
+	}
+
+	t.Run("option", func(t *testing.T) {
+		var rec bytes.Buffer
+		output := run(t, WithRecorder(&rec))
+		check(t, output, rec.String())
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "session.cast")
+		output := run(t, WithEnvironment([]string{recordEnv + "=" + path}))
+
+		rec, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, output, string(rec))
+	})
+}
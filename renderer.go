@@ -1,84 +1,144 @@
 package tea
 
-// renderer is the interface for Bubble Tea renderers.
-type renderer interface {
+// Renderer is the interface for Bubble Tea renderers. A custom renderer can
+// be supplied with [WithRenderer].
+//
+// Besides drawing frames, the renderer is responsible for the terminal modes
+// a program toggles, such as the alternate screen, mouse tracking, bracketed
+// paste and focus reporting, so a custom renderer sees every mode change.
+//
+// A renderer can also implement a HandleMessage(Msg) method, in which case
+// it's called with every message before the model's Update, e.g. to track
+// [WindowSizeMsg], and a SetCursor(*Cursor) method, in which case it's
+// called after every Write with the cursor of models implementing
+// [CursorModel]. The keyboard protocols, see [WithKeyboardEnhancements] and
+// [WithModifyOtherKeys], are only enabled by renderers implementing the
+// KeyboardEnhancements, EnableKeyboardEnhancements,
+// DisableKeyboardEnhancements, ModifyOtherKeys, EnableModifyOtherKeys and
+// DisableModifyOtherKeys methods.
+type Renderer interface {
 	// Start the renderer.
-	start()
+	Start()
 
 	// Stop the renderer, but render the final frame in the buffer, if any.
-	stop()
+	Stop()
 
 	// Stop the renderer without doing any final rendering.
-	kill()
+	Kill()
 
 	// Write a frame to the renderer. The renderer can write this data to
 	// output at its discretion.
-	write(string)
+	Write(string)
 
 	// Request a full re-render. Note that this will not trigger a render
 	// immediately. Rather, this method causes the next render to be a full
 	// repaint. Because of this, it's safe to call this method multiple times
 	// in succession.
-	repaint()
+	Repaint()
 
 	// Clears the terminal.
-	clearScreen()
+	ClearScreen()
 
 	// Whether or not the alternate screen buffer is enabled.
-	altScreen() bool
+	AltScreen() bool
 	// Enable the alternate screen buffer.
-	enterAltScreen()
+	EnterAltScreen()
 	// Disable the alternate screen buffer.
-	exitAltScreen()
+	ExitAltScreen()
 
 	// Show the cursor.
-	showCursor()
+	ShowCursor()
 	// Hide the cursor.
-	hideCursor()
+	HideCursor()
 
-	// enableMouseCellMotion enables mouse click, release, wheel and motion
+	// EnableMouseCellMotion enables mouse click, release, wheel and motion
 	// events if a mouse button is pressed (i.e., drag events).
-	enableMouseCellMotion()
+	EnableMouseCellMotion()
 
-	// disableMouseCellMotion disables Mouse Cell Motion tracking.
-	disableMouseCellMotion()
+	// DisableMouseCellMotion disables Mouse Cell Motion tracking.
+	DisableMouseCellMotion()
 
-	// enableMouseAllMotion enables mouse click, release, wheel and motion
+	// EnableMouseAllMotion enables mouse click, release, wheel and motion
 	// events, regardless of whether a mouse button is pressed. Many modern
 	// terminals support this, but not all.
-	enableMouseAllMotion()
+	EnableMouseAllMotion()
 
-	// disableMouseAllMotion disables All Motion mouse tracking.
-	disableMouseAllMotion()
+	// DisableMouseAllMotion disables All Motion mouse tracking.
+	DisableMouseAllMotion()
 
-	// enableMouseSGRMode enables mouse extended mode (SGR).
-	enableMouseSGRMode()
+	// EnableMouseSGRMode enables mouse extended mode (SGR).
+	EnableMouseSGRMode()
 
-	// disableMouseSGRMode disables mouse extended mode (SGR).
-	disableMouseSGRMode()
+	// DisableMouseSGRMode disables mouse extended mode (SGR).
+	DisableMouseSGRMode()
 
-	// enableBracketedPaste enables bracketed paste, where characters
+	// EnableBracketedPaste enables bracketed paste, where characters
 	// inside the input are not interpreted when pasted as a whole.
-	enableBracketedPaste()
+	EnableBracketedPaste()
 
-	// disableBracketedPaste disables bracketed paste.
-	disableBracketedPaste()
+	// DisableBracketedPaste disables bracketed paste.
+	DisableBracketedPaste()
 
-	// bracketedPasteActive reports whether bracketed paste mode is
+	// BracketedPasteActive reports whether bracketed paste mode is
 	// currently enabled.
-	bracketedPasteActive() bool
+	BracketedPasteActive() bool
 
-	// setWindowTitle sets the terminal window title.
-	setWindowTitle(string)
+	// SetWindowTitle sets the terminal window title.
+	SetWindowTitle(string)
 
-	// reportFocus returns whether reporting focus events is enabled.
-	reportFocus() bool
+	// ReportFocus returns whether reporting focus events is enabled.
+	ReportFocus() bool
 
-	// enableReportFocus reports focus events to the program.
-	enableReportFocus()
+	// EnableReportFocus reports focus events to the program.
+	EnableReportFocus()
 
-	// disableReportFocus stops reporting focus events to the program.
-	disableReportFocus()
+	// DisableReportFocus stops reporting focus events to the program.
+	DisableReportFocus()
+}
+
+// msgHandler is implemented by renderers that want to see the messages
+// processed by the program.
+type msgHandler interface {
+	HandleMessage(Msg)
+}
+
+// sequenceWriter is implemented by renderers that write escape sequences on
+// behalf of the program, such as clipboard requests, so that they don't
+// interleave with frames. Sequences are written straight to the output for
+// renderers that don't implement it.
+type sequenceWriter interface {
+	WriteSequence(string)
+}
+
+// keyboardEnhancer is implemented by renderers that can enable the kitty
+// keyboard protocol and xterm's modifyOtherKeys mode.
+type keyboardEnhancer interface {
+	// KeyboardEnhancements returns the keyboard enhancements that are
+	// currently enabled.
+	KeyboardEnhancements() KeyboardEnhancements
+
+	// EnableKeyboardEnhancements pushes the given kitty keyboard protocol
+	// enhancements onto the terminal's keyboard stack.
+	EnableKeyboardEnhancements(KeyboardEnhancements)
+
+	// DisableKeyboardEnhancements pops the keyboard enhancements pushed by
+	// EnableKeyboardEnhancements.
+	DisableKeyboardEnhancements()
+
+	// ModifyOtherKeys returns whether modifyOtherKeys is enabled.
+	ModifyOtherKeys() bool
+
+	// EnableModifyOtherKeys enables xterm's modifyOtherKeys mode, at level 2.
+	EnableModifyOtherKeys()
+
+	// DisableModifyOtherKeys resets modifyOtherKeys.
+	DisableModifyOtherKeys()
+}
+
+// syncOutputSetter is implemented by renderers that can write frames as
+// synchronized updates (mode 2026).
+type syncOutputSetter interface {
+	SetSynchronizedOutput(bool)
 }
 
 // repaintMsg forces a full repaint.
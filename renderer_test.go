@@ -0,0 +1,258 @@
+package tea
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/charmbracelet/bubbletea/vt"
+)
+
+// recordingRenderer is a custom renderer that records the frames and the
+// messages it sees, along with the mode changes.
+type recordingRenderer struct {
+	nilRenderer
+
+	mtx    sync.Mutex
+	frames []string
+	sizes  []WindowSizeMsg
+	alt    bool
+}
+
+func (r *recordingRenderer) Write(s string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if len(r.frames) == 0 || r.frames[len(r.frames)-1] != s {
+		r.frames = append(r.frames, s)
+	}
+}
+
+func (r *recordingRenderer) AltScreen() bool {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.alt
+}
+
+func (r *recordingRenderer) EnterAltScreen() {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.alt = true
+}
+
+func (r *recordingRenderer) ExitAltScreen() {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.alt = false
+}
+
+func (r *recordingRenderer) HandleMessage(msg Msg) {
+	if msg, ok := msg.(WindowSizeMsg); ok {
+		r.mtx.Lock()
+		r.sizes = append(r.sizes, msg)
+		r.mtx.Unlock()
+	}
+}
+
+func TestCustomRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+
+	r := &recordingRenderer{}
+	m := &testModel{}
+	p := NewProgram(m, WithInput(&in), WithOutput(&buf), WithRenderer(r), WithAltScreen())
+
+	go p.Send(sequenceMsg{
+		func() Msg { return WindowSizeMsg{80, 24} },
+		func() Msg { return incrementMsg{} },
+		Quit,
+	})
+
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("expected no output from the standard renderer, got %q", buf.String())
+	}
+	if expected := []string{"success\n"}; !reflect.DeepEqual(r.frames, expected) {
+		t.Errorf("expected frames %q, got %q", expected, r.frames)
+	}
+	if expected := []WindowSizeMsg{{80, 24}}; !reflect.DeepEqual(r.sizes, expected) {
+		t.Errorf("expected window sizes %v, got %v", expected, r.sizes)
+	}
+	if r.alt {
+		t.Error("expected the alt screen to be exited on shutdown")
+	}
+}
+
+// keyboardRenderer is a custom renderer that records the keyboard protocols
+// it's asked to enable and disable.
+type keyboardRenderer struct {
+	recordingRenderer
+
+	enhancements    KeyboardEnhancements
+	modifyOtherKeys bool
+	calls           []string
+}
+
+func (r *keyboardRenderer) KeyboardEnhancements() KeyboardEnhancements {
+	return r.enhancements
+}
+
+func (r *keyboardRenderer) EnableKeyboardEnhancements(e KeyboardEnhancements) {
+	r.enhancements = e
+	r.calls = append(r.calls, fmt.Sprintf("enable %d", e))
+}
+
+func (r *keyboardRenderer) DisableKeyboardEnhancements() {
+	r.enhancements = 0
+	r.calls = append(r.calls, "disable")
+}
+
+func (r *keyboardRenderer) ModifyOtherKeys() bool {
+	return r.modifyOtherKeys
+}
+
+func (r *keyboardRenderer) EnableModifyOtherKeys() {
+	r.modifyOtherKeys = true
+	r.calls = append(r.calls, "enable modifyOtherKeys")
+}
+
+func (r *keyboardRenderer) DisableModifyOtherKeys() {
+	r.modifyOtherKeys = false
+	r.calls = append(r.calls, "disable modifyOtherKeys")
+}
+
+func TestCustomRendererKeyboardProtocols(t *testing.T) {
+	run := func(t *testing.T, opts ...ProgramOption) string {
+		t.Helper()
+		var buf, in bytes.Buffer
+		opts = append(opts,
+			WithInput(&in),
+			WithOutput(&buf),
+			WithKeyboardEnhancements(ReportEventTypes),
+			WithModifyOtherKeys(),
+		)
+		p := NewProgram(&testModel{}, opts...)
+		go p.Send(Quit())
+		if _, err := p.Run(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	t.Run("supported", func(t *testing.T) {
+		r := &keyboardRenderer{}
+		run(t, WithRenderer(r))
+		want := []string{
+			fmt.Sprintf("enable %d", DisambiguateEscapeCodes|ReportEventTypes),
+			"enable modifyOtherKeys",
+			"disable",
+			"disable modifyOtherKeys",
+		}
+		if !reflect.DeepEqual(r.calls, want) {
+			t.Errorf("expected calls %q, got %q", want, r.calls)
+		}
+	})
+
+	// Renderers that don't support the keyboard protocols leave the
+	// terminal alone.
+	t.Run("unsupported", func(t *testing.T) {
+		if out := run(t, WithRenderer(&recordingRenderer{})); out != "" {
+			t.Errorf("expected no output, got %q", out)
+		}
+	})
+
+	t.Run("no renderer", func(t *testing.T) {
+		if out := run(t, WithoutRenderer()); out != "" {
+			t.Errorf("expected no output, got %q", out)
+		}
+	})
+}
+
+func TestStandardRendererScreen(t *testing.T) {
+	tests := []struct {
+		name     string
+		frames   []string
+		expected string
+	}{
+		{
+			name:     "shrink",
+			frames:   []string{"hello world\nsecond line\nthird", "hi\nthere"},
+			expected: "hi\nthere\n\n",
+		},
+		{
+			name:     "grow",
+			frames:   []string{"one", "one\ntwo\nthree"},
+			expected: "one\ntwo\nthree\n",
+		},
+		{
+			name:     "shorter lines",
+			frames:   []string{"abcdef\nghijkl", "abc\nghijkl", "a\nb"},
+			expected: "a\nb\n\n",
+		},
+		{
+			name:     "wide characters",
+			frames:   []string{"世界 hello", "世 hello"},
+			expected: "世 hello\n\n\n",
+		},
+		{
+			name:     "styles",
+			frames:   []string{"\x1b[1mbold\x1b[m plain", "\x1b[1mbold\x1b[m PLAIN"},
+			expected: "bold PLAIN\n\n\n",
+		},
+		{
+			name:     "taller than the terminal",
+			frames:   []string{"1\n2\n3\n4\n5\n6", "1\n2\n3\n4\nx\n6"},
+			expected: "3\n4\nx\n6",
+		},
+	}
+
+	for _, test := range tests {
+		for _, cells := range []bool{false, true} {
+			name := test.name
+			if cells {
+				name += "_cells"
+			}
+
+			t.Run(name, func(t *testing.T) {
+				term := vt.New(20, 4)
+				r := newRenderer(term, false, 60, cells).(*standardRenderer)
+				r.HandleMessage(WindowSizeMsg{Width: 20, Height: 4})
+
+				for _, frame := range test.frames {
+					r.Write(frame)
+					r.flush()
+				}
+				if got := term.String(); got != test.expected {
+					t.Errorf("expected screen:\n%q\ngot:\n%q", test.expected, got)
+				}
+			})
+		}
+	}
+}
+
+func TestStandardRendererScrollArea(t *testing.T) {
+	term := vt.New(20, 5)
+	r := newRenderer(term, false, 60, false).(*standardRenderer)
+	r.HandleMessage(WindowSizeMsg{Width: 20, Height: 5})
+	r.EnterAltScreen()
+
+	r.Write("header\n\n\n\nfooter")
+	r.flush()
+
+	// Lines 1 through 3 are handed over to the scroll area.
+	r.HandleMessage(syncScrollAreaMsg{lines: []string{"a", "b", "c"}, topBoundary: 2, bottomBoundary: 4})
+	r.flush()
+	r.HandleMessage(scrollDownMsg{lines: []string{"d"}, topBoundary: 2, bottomBoundary: 4})
+	r.flush()
+
+	if expected := "header\nb\nc\nd\nfooter"; term.String() != expected {
+		t.Errorf("expected screen:\n%q\ngot:\n%q", expected, term.String())
+	}
+	if !term.AltScreen() {
+		t.Error("expected the alt screen to be active")
+	}
+}
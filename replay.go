@@ -0,0 +1,259 @@
+package tea
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReplayEvent is an input event replayed by a program, see [WithReplay].
+type ReplayEvent struct {
+	// At is when the event occurs, relative to the start of the program.
+	At time.Duration
+
+	// Input is raw terminal input. It's parsed the same way as input read
+	// from the terminal.
+	Input string
+
+	// Width and Height, when set, make the event a window size change
+	// rather than input.
+	Width, Height int
+}
+
+// ReplayInput returns an event replaying raw terminal input, such as escape
+// sequences.
+func ReplayInput(at time.Duration, input string) ReplayEvent {
+	return ReplayEvent{At: at, Input: input}
+}
+
+// ReplayKey returns an event replaying a key press. The key is encoded the
+// way a terminal would send it.
+func ReplayKey(at time.Duration, k Key) ReplayEvent {
+	return ReplayEvent{At: at, Input: encodeKey(k)}
+}
+
+// ReplayPaste returns an event replaying text pasted using bracketed paste.
+func ReplayPaste(at time.Duration, text string) ReplayEvent {
+	return ReplayEvent{At: at, Input: "\x1b[200~" + text + "\x1b[201~"}
+}
+
+// ReplayMouse returns an event replaying a mouse event. The event is encoded
+// using SGR mouse encoding.
+func ReplayMouse(at time.Duration, m MouseEvent) ReplayEvent {
+	return ReplayEvent{At: at, Input: encodeMouse(m)}
+}
+
+// ReplayResize returns an event replaying a window size change.
+func ReplayResize(at time.Duration, width, height int) ReplayEvent {
+	return ReplayEvent{At: at, Width: width, Height: height}
+}
+
+// LoadAsciicast loads the input and window size changes of an asciicast v2
+// recording, such as one made with [WithRecorder], as replay events. The
+// size of the terminal at the start of the recording is the first event.
+func LoadAsciicast(r io.Reader) ([]ReplayEvent, error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20) //nolint:mnd
+
+	if !s.Scan() {
+		if err := s.Err(); err != nil {
+			return nil, fmt.Errorf("error reading asciicast: %w", err)
+		}
+		return nil, errors.New("asciicast is empty")
+	}
+	var header asciicastHeader
+	if err := json.Unmarshal(s.Bytes(), &header); err != nil {
+		return nil, fmt.Errorf("invalid asciicast header: %w", err)
+	}
+	if header.Version != 2 { //nolint:mnd
+		return nil, fmt.Errorf("unsupported asciicast version %d", header.Version)
+	}
+
+	events := []ReplayEvent{ReplayResize(0, header.Width, header.Height)}
+	for line := 2; s.Scan(); line++ {
+		if len(strings.TrimSpace(s.Text())) == 0 {
+			continue
+		}
+
+		var ev [3]any
+		if err := json.Unmarshal(s.Bytes(), &ev); err != nil {
+			return nil, fmt.Errorf("invalid asciicast event on line %d: %w", line, err)
+		}
+		t, ok1 := ev[0].(float64)
+		kind, ok2 := ev[1].(string)
+		data, ok3 := ev[2].(string)
+		if !ok1 || !ok2 || !ok3 {
+			return nil, fmt.Errorf("invalid asciicast event on line %d", line)
+		}
+		at := time.Duration(t * float64(time.Second))
+
+		switch kind {
+		case recordInput:
+			events = append(events, ReplayInput(at, data))
+		case recordResize:
+			w, h, _ := strings.Cut(data, "x")
+			width, err1 := strconv.Atoi(w)
+			height, err2 := strconv.Atoi(h)
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("invalid asciicast resize event on line %d: %q", line, data)
+			}
+			events = append(events, ReplayResize(at, width, height))
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("error reading asciicast: %w", err)
+	}
+	return events, nil
+}
+
+// replay sends the replay events to the program at their time, parsing
+// input like input read from the terminal.
+func (p *Program) replay() chan struct{} {
+	ch := make(chan struct{})
+
+	go func() {
+		defer close(ch)
+
+		start := time.Now()
+		for _, ev := range p.replayEvents {
+			if d := time.Until(start.Add(ev.At)); d > 0 {
+				t := time.NewTimer(d)
+				select {
+				case <-p.ctx.Done():
+					t.Stop()
+					return
+				case <-t.C:
+				}
+			}
+
+			if ev.Width > 0 || ev.Height > 0 {
+				p.Resize(ev.Width, ev.Height)
+				continue
+			}
+
+			err := readAnsiInputs(p.ctx, p.msgs, strings.NewReader(ev.Input), p.parser, 0)
+			if p.ctx.Err() != nil {
+				return
+			}
+			if err != nil && !errors.Is(err, io.EOF) {
+				p.reportError(ErrorSourceInput, err)
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+// keySequence identifies a key that isn't text.
+type keySequence struct {
+	t   KeyType
+	alt bool
+}
+
+// replayKeySequences maps keys that aren't text to the shortest sequence that
+// produces them.
+var replayKeySequences = func() map[keySequence]string {
+	seqs := make([]string, 0, len(extSequences))
+	for seq := range extSequences {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool {
+		if len(seqs[i]) != len(seqs[j]) {
+			return len(seqs[i]) < len(seqs[j])
+		}
+		return seqs[i] < seqs[j]
+	})
+
+	m := map[keySequence]string{}
+	for _, seq := range seqs {
+		k := extSequences[seq]
+		if k.Runes != nil {
+			continue
+		}
+		if _, ok := m[keySequence{k.Type, k.Alt}]; !ok {
+			m[keySequence{k.Type, k.Alt}] = seq
+		}
+	}
+	return m
+}()
+
+// encodeKey returns the input a terminal sends for the given key.
+func encodeKey(k Key) string {
+	var prefix string
+	if k.Alt {
+		prefix = "\x1b"
+	}
+
+	switch k.Type {
+	case KeyRunes:
+		if k.Paste {
+			return "\x1b[200~" + string(k.Runes) + "\x1b[201~"
+		}
+		if mods := k.Mod &^ (ModShift | ModAlt | ModCapsLock | ModNumLock); mods != 0 && len(k.Runes) == 1 {
+			// There's no legacy encoding for these modifiers.
+			mod := k.Mod
+			if k.Alt {
+				mod |= ModAlt
+			}
+			return "\x1b[" + strconv.Itoa(int(k.Runes[0])) + ";" + strconv.Itoa(int(mod)+1) + "u"
+		}
+		return prefix + string(k.Runes)
+	case KeySpace:
+		return prefix + " "
+	case keyNUL:
+		return prefix + "\x00"
+	case KeyEscape:
+		if !k.Alt {
+			return "\x1b"
+		}
+	}
+
+	if seq, ok := replayKeySequences[keySequence{k.Type, k.Alt}]; ok {
+		return seq
+	}
+	if seq, ok := replayKeySequences[keySequence{k.Type, false}]; ok {
+		return prefix + seq
+	}
+	return ""
+}
+
+// encodeMouse returns the input a terminal sends for the given mouse event
+// using SGR mouse encoding.
+func encodeMouse(m MouseEvent) string {
+	var b int
+	switch {
+	case m.Button == MouseButtonNone:
+		b = 3 //nolint:mnd
+	case m.Button >= MouseButtonBackward:
+		b = 128 + int(m.Button-MouseButtonBackward) //nolint:mnd
+	case m.Button >= MouseButtonWheelUp:
+		b = 64 + int(m.Button-MouseButtonWheelUp) //nolint:mnd
+	default:
+		b = int(m.Button - MouseButtonLeft)
+	}
+	if m.Shift {
+		b |= 4
+	}
+	if m.Alt {
+		b |= 8
+	}
+	if m.Ctrl {
+		b |= 16
+	}
+	if m.Action == MouseActionMotion {
+		b |= 32
+	}
+
+	final := 'M'
+	if m.Action == MouseActionRelease {
+		final = 'm'
+	}
+	return fmt.Sprintf("\x1b[<%d;%d;%d%c", b, m.X+1, m.Y+1, final)
+}
@@ -0,0 +1,152 @@
+package tea
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEncodeKey(t *testing.T) {
+	var keys []Key
+	for kt := range keyNames {
+		if kt == KeyRunes || kt == KeyEscape {
+			continue
+		}
+		if encodeKey(Key{Type: kt}) == "" {
+			continue
+		}
+		keys = append(keys, Key{Type: kt}, Key{Type: kt, Alt: true})
+	}
+	keys = append(keys,
+		Key{Type: KeyEscape},
+		Key{Type: KeyRunes, Runes: []rune("a")},
+		Key{Type: KeyRunes, Runes: []rune("世"), Alt: true},
+		Key{Type: KeyRunes, Runes: []rune("a"), Mod: ModSuper},
+		Key{Type: KeyRunes, Runes: []rune("a"), Mod: ModSuper, Alt: true},
+	)
+
+	for _, k := range keys {
+		input := encodeKey(k)
+		_, msg := detectOneMsg([]byte(input), false)
+		got, ok := msg.(KeyMsg)
+		if !ok {
+			t.Errorf("%q: expected a key from %q, got %T", k, input, msg)
+			continue
+		}
+		if got.String() != k.String() {
+			t.Errorf("%q: expected %q from %q", k, got, input)
+		}
+	}
+}
+
+func TestEncodeMouse(t *testing.T) {
+	events := []MouseEvent{
+		{X: 0, Y: 0, Button: MouseButtonLeft, Action: MouseActionPress},
+		{X: 10, Y: 5, Button: MouseButtonRight, Action: MouseActionRelease},
+		{X: 3, Y: 7, Button: MouseButtonWheelDown, Action: MouseActionPress, Ctrl: true},
+		{X: 1, Y: 2, Button: MouseButtonNone, Action: MouseActionMotion, Alt: true},
+		{X: 4, Y: 4, Button: MouseButtonBackward, Action: MouseActionPress, Shift: true},
+	}
+	for _, m := range events {
+		input := encodeMouse(m)
+		_, msg := detectOneMsg([]byte(input), false)
+		got, ok := msg.(MouseMsg)
+		if !ok {
+			t.Errorf("%v: expected a mouse event from %q, got %T", m, input, msg)
+			continue
+		}
+		got.Type = m.Type // deprecated
+		if MouseEvent(got) != m {
+			t.Errorf("expected %+v from %q, got %+v", m, input, got)
+		}
+	}
+}
+
+func TestLoadAsciicast(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRecorder(&buf)
+	r.begin(100, 30, nil)
+	r.output([]byte("hello"))
+	r.input([]byte("\x1b[A"))
+	r.resize(120, 40)
+	r.input([]byte("q"))
+
+	events, err := LoadAsciicast(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, ev := range events {
+		if ev.Width > 0 {
+			got = append(got, fmt.Sprintf("%dx%d", ev.Width, ev.Height))
+		} else {
+			got = append(got, ev.Input)
+		}
+	}
+	if want := []string{"100x30", "\x1b[A", "120x40", "q"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected events %q, got %q", want, got)
+	}
+
+	for _, data := range []string{"", "{}", `{"version": 2}` + "\n[0.1, \"i\"]"} {
+		if _, err := LoadAsciicast(strings.NewReader(data)); err == nil {
+			t.Errorf("expected an error loading %q", data)
+		}
+	}
+}
+
+type replayModel struct {
+	mtx  sync.Mutex
+	msgs []string
+}
+
+func (m *replayModel) Init() Cmd {
+	return nil
+}
+
+func (m *replayModel) Update(msg Msg) (Model, Cmd) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	switch msg := msg.(type) {
+	case KeyMsg:
+		m.msgs = append(m.msgs, msg.String())
+		if msg.String() == "q" {
+			return m, Quit
+		}
+	case MouseMsg:
+		m.msgs = append(m.msgs, msg.String())
+	case WindowSizeMsg:
+		m.msgs = append(m.msgs, fmt.Sprintf("%dx%d", msg.Width, msg.Height))
+	}
+	return m, nil
+}
+
+func (m *replayModel) View() string {
+	return ""
+}
+
+func TestProgramReplay(t *testing.T) {
+	m := &replayModel{}
+	var buf bytes.Buffer
+	p := NewProgram(m, WithInput(nil), WithOutput(&buf), WithReplay([]ReplayEvent{
+		ReplayResize(0, 100, 30),
+		ReplayKey(time.Millisecond, Key{Type: KeyDown}),
+		ReplayPaste(2*time.Millisecond, "hi"),
+		ReplayMouse(3*time.Millisecond, MouseEvent{X: 1, Y: 2, Button: MouseButtonLeft, Action: MouseActionPress}),
+		ReplayInput(4*time.Millisecond, "q"),
+	}))
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	want := []string{"100x30", "down", "[hi]", "left press", "q"}
+	if strings.Join(m.msgs, ",") != strings.Join(want, ",") {
+		t.Errorf("expected messages %q, got %q", want, m.msgs)
+	}
+}
@@ -170,7 +170,7 @@ func DisableReportFocus() Msg {
 // Deprecated: Use the WithAltScreen ProgramOption instead.
 func (p *Program) EnterAltScreen() {
 	if p.renderer != nil {
-		p.renderer.enterAltScreen()
+		p.renderer.EnterAltScreen()
 	} else {
 		p.startupOptions |= withAltScreen
 	}
@@ -181,7 +181,7 @@ func (p *Program) EnterAltScreen() {
 // Deprecated: The altscreen will exited automatically when the program exits.
 func (p *Program) ExitAltScreen() {
 	if p.renderer != nil {
-		p.renderer.exitAltScreen()
+		p.renderer.ExitAltScreen()
 	} else {
 		p.startupOptions &^= withAltScreen
 	}
@@ -193,7 +193,7 @@ func (p *Program) ExitAltScreen() {
 // Deprecated: Use the WithMouseCellMotion ProgramOption instead.
 func (p *Program) EnableMouseCellMotion() {
 	if p.renderer != nil {
-		p.renderer.enableMouseCellMotion()
+		p.renderer.EnableMouseCellMotion()
 	} else {
 		p.startupOptions |= withMouseCellMotion
 	}
@@ -205,7 +205,7 @@ func (p *Program) EnableMouseCellMotion() {
 // Deprecated: The mouse will automatically be disabled when the program exits.
 func (p *Program) DisableMouseCellMotion() {
 	if p.renderer != nil {
-		p.renderer.disableMouseCellMotion()
+		p.renderer.DisableMouseCellMotion()
 	} else {
 		p.startupOptions &^= withMouseCellMotion
 	}
@@ -218,7 +218,7 @@ func (p *Program) DisableMouseCellMotion() {
 // Deprecated: Use the WithMouseAllMotion ProgramOption instead.
 func (p *Program) EnableMouseAllMotion() {
 	if p.renderer != nil {
-		p.renderer.enableMouseAllMotion()
+		p.renderer.EnableMouseAllMotion()
 	} else {
 		p.startupOptions |= withMouseAllMotion
 	}
@@ -230,7 +230,7 @@ func (p *Program) EnableMouseAllMotion() {
 // Deprecated: The mouse will automatically be disabled when the program exits.
 func (p *Program) DisableMouseAllMotion() {
 	if p.renderer != nil {
-		p.renderer.disableMouseAllMotion()
+		p.renderer.DisableMouseAllMotion()
 	} else {
 		p.startupOptions &^= withMouseAllMotion
 	}
@@ -241,7 +241,7 @@ func (p *Program) DisableMouseAllMotion() {
 // Deprecated: Use the SetWindowTitle command instead.
 func (p *Program) SetWindowTitle(title string) {
 	if p.renderer != nil {
-		p.renderer.setWindowTitle(title)
+		p.renderer.SetWindowTitle(title)
 	} else {
 		p.startupTitle = title
 	}
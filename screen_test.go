@@ -51,6 +51,16 @@ func TestClearMsg(t *testing.T) {
 			cmds:     []Cmd{HideCursor, ShowCursor},
 			expected: "\x1b[?25l\x1b[?2004h\x1b[?25l\x1b[?25h\rsuccess\x1b[K\r\n\x1b[K\x1b[80D\x1b[2K\r\x1b[?2004l\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[?1006l",
 		},
+		{
+			name:     "clipboard_set",
+			cmds:     []Cmd{SetClipboard("hi"), SetPrimaryClipboard("")},
+			expected: "\x1b[?25l\x1b[?2004h\x1b]52;c;aGk=\a\x1b]52;p;\a\rsuccess\x1b[K\r\n\x1b[K\x1b[80D\x1b[2K\r\x1b[?2004l\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[?1006l",
+		},
+		{
+			name:     "clipboard_read",
+			cmds:     []Cmd{ReadClipboard()},
+			expected: "\x1b[?25l\x1b[?2004h\x1b]52;c;?\a\rsuccess\x1b[K\r\n\x1b[K\x1b[80D\x1b[2K\r\x1b[?2004l\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[?1006l",
+		},
 		{
 			name:     "bp_stop_start",
 			cmds:     []Cmd{DisableBracketedPaste, EnableBracketedPaste},
@@ -59,24 +69,70 @@ func TestClearMsg(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			var buf bytes.Buffer
-			var in bytes.Buffer
+		test.cmds = append([]Cmd{func() Msg { return WindowSizeMsg{80, 24} }}, test.cmds...)
+		test.cmds = append(test.cmds, Quit)
 
-			m := &testModel{}
-			p := NewProgram(m, WithInput(&in), WithOutput(&buf))
+		for _, cells := range []bool{false, true} {
+			name := test.name
+			opts := []ProgramOption{}
+			if cells {
+				name += "_cells"
+				opts = append(opts, WithCellRenderer())
+			}
 
-			test.cmds = append([]Cmd{func() Msg { return WindowSizeMsg{80, 24} }}, test.cmds...)
-			test.cmds = append(test.cmds, Quit)
-			go p.Send(test.cmds)
+			t.Run(name, func(t *testing.T) {
+				var buf bytes.Buffer
+				var in bytes.Buffer
 
-			if _, err := p.Run(); err != nil {
-				t.Fatal(err)
-			}
+				m := &testModel{}
+				p := NewProgram(m, append(opts, WithInput(&in), WithOutput(&buf))...)
 
-			if buf.String() != test.expected {
-				t.Errorf("expected embedded sequence:\n%q\ngot:\n%q", test.expected, buf.String())
-			}
-		})
+				go p.Send(test.cmds)
+
+				if _, err := p.Run(); err != nil {
+					t.Fatal(err)
+				}
+
+				if buf.String() != test.expected {
+					t.Errorf("expected embedded sequence:\n%q\ngot:\n%q", test.expected, buf.String())
+				}
+			})
+		}
+	}
+}
+
+func TestKeyboardEnhancementsSequences(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+
+	m := &testModel{}
+	p := NewProgram(m, WithInput(&in), WithOutput(&buf), WithKeyboardEnhancements(ReportAlternateKeys))
+	go p.Send(sequenceMsg{func() Msg { return WindowSizeMsg{80, 24} }, Quit})
+
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "\x1b[?25l\x1b[?2004h\x1b[>5u\rsuccess\x1b[K\r\n\x1b[K\x1b[80D\x1b[2K\r\x1b[?2004l\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[?1006l\x1b[<1u"
+	if buf.String() != expected {
+		t.Errorf("expected embedded sequence:\n%q\ngot:\n%q", expected, buf.String())
+	}
+}
+
+func TestModifyOtherKeysSequences(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+
+	m := &testModel{}
+	p := NewProgram(m, WithInput(&in), WithOutput(&buf), WithModifyOtherKeys())
+	go p.Send(sequenceMsg{func() Msg { return WindowSizeMsg{80, 24} }, Quit})
+
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "\x1b[?25l\x1b[?2004h\x1b[>4;2m\rsuccess\x1b[K\r\n\x1b[K\x1b[80D\x1b[2K\r\x1b[?2004l\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[?1006l\x1b[>4m"
+	if buf.String() != expected {
+		t.Errorf("expected embedded sequence:\n%q\ngot:\n%q", expected, buf.String())
 	}
 }
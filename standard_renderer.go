@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/charmbracelet/bubbletea/vt"
 	"github.com/charmbracelet/x/ansi"
 	"github.com/muesli/ansi/compressor"
 )
@@ -43,6 +44,28 @@ type standardRenderer struct {
 	// cursor visibility state
 	cursorHidden bool
 
+	// the cursor placed by the model, if any, and whether it needs to be
+	// placed again
+	cursor      *Cursor
+	cursorDirty bool
+
+	// whether the cursor was moved away from the start of the last line to
+	// the model's cursor, and if so how many lines up
+	cursorPlaced bool
+	cursorUp     int
+
+	// whether the cursor is shown for the model, and the cursor style set
+	// for it; zero if unchanged
+	cursorShown bool
+	cursorStyle int
+
+	// the number of lines dropped from the top of the last frame because
+	// it was taller than the window
+	linesDropped int
+
+	// whether to wrap frames in synchronized updates (mode 2026)
+	syncOutput bool
+
 	// essentially whether or not we're using the full size of the terminal
 	altScreenActive bool
 
@@ -52,17 +75,28 @@ type standardRenderer struct {
 	// reportingFocus whether reporting focus events is enabled
 	reportingFocus bool
 
+	// the keyboard enhancements pushed onto the terminal's keyboard stack
+	enhancements KeyboardEnhancements
+
+	// whether xterm's modifyOtherKeys mode is enabled
+	modifyOtherKeys bool
+
 	// renderer dimensions; usually the size of the window
 	width  int
 	height int
 
 	// lines explicitly set not to render
 	ignoreLines map[int]struct{}
+
+	// whether to diff frames cell by cell rather than line by line, and the
+	// cells of the last frame rendered when doing so
+	cellDiff  bool
+	lastCells [][]cell
 }
 
 // newRenderer creates a new renderer. Normally you'll want to initialize it
 // with os.Stdout as the first argument.
-func newRenderer(out io.Writer, useANSICompressor bool, fps int) renderer {
+func newRenderer(out io.Writer, useANSICompressor bool, fps int, cellDiff bool) Renderer {
 	if fps < 1 {
 		fps = defaultFPS
 	} else if fps > maxFPS {
@@ -75,6 +109,7 @@ func newRenderer(out io.Writer, useANSICompressor bool, fps int) renderer {
 		framerate:          time.Second / time.Duration(fps),
 		useANSICompressor:  useANSICompressor,
 		queuedMessageLines: []string{},
+		cellDiff:           cellDiff,
 	}
 	if r.useANSICompressor {
 		r.out = &compressor.Writer{Forward: out}
@@ -82,8 +117,8 @@ func newRenderer(out io.Writer, useANSICompressor bool, fps int) renderer {
 	return r
 }
 
-// start starts the renderer.
-func (r *standardRenderer) start() {
+// Start starts the renderer.
+func (r *standardRenderer) Start() {
 	if r.ticker == nil {
 		r.ticker = time.NewTicker(r.framerate)
 	} else {
@@ -99,8 +134,8 @@ func (r *standardRenderer) start() {
 	go r.listen()
 }
 
-// stop permanently halts the renderer, rendering the final frame.
-func (r *standardRenderer) stop() {
+// Stop permanently halts the renderer, rendering the final frame.
+func (r *standardRenderer) Stop() {
 	// Stop the renderer before acquiring the mutex to avoid a deadlock.
 	r.once.Do(func() {
 		r.done <- struct{}{}
@@ -112,6 +147,7 @@ func (r *standardRenderer) stop() {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
+	r.resetCursor()
 	r.execute(ansi.EraseEntireLine)
 	// Move the cursor back to the beginning of the line
 	r.execute("\r")
@@ -128,8 +164,109 @@ func (r *standardRenderer) execute(seq string) {
 	_, _ = io.WriteString(r.out, seq)
 }
 
-// kill halts the renderer. The final frame will not be rendered.
-func (r *standardRenderer) kill() {
+// SetCursor sets the cursor the model placed in its view, which is shown
+// after the next frame. A nil cursor hides it.
+func (r *standardRenderer) SetCursor(c *Cursor) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if c == nil && r.cursor == nil || c != nil && r.cursor != nil && *c == *r.cursor {
+		return
+	}
+	if c != nil {
+		cursor := *c
+		c = &cursor
+	}
+	r.cursor = c
+	r.cursorDirty = true
+}
+
+// placeCursor moves the cursor from the start of the last line of a frame to
+// the model's cursor, if any, and shows it.
+func (r *standardRenderer) placeCursor(buf *bytes.Buffer, lines int) {
+	r.cursorDirty = false
+
+	c := r.cursor
+	if c == nil || lines == 0 {
+		if r.cursorShown {
+			r.cursorShown = false
+			if r.cursorHidden {
+				buf.WriteString(ansi.HideCursor)
+			}
+		}
+		return
+	}
+
+	y := min(max(c.Y-r.linesDropped, 0), lines-1)
+	x := max(c.X, 0)
+	if r.width > 0 {
+		x = min(x, r.width-1)
+	}
+	if r.altScreenActive {
+		buf.WriteString(ansi.CursorPosition(x+1, y+1))
+	} else {
+		r.cursorUp = lines - 1 - y
+		if r.cursorUp > 0 {
+			buf.WriteString(ansi.CursorUp(r.cursorUp))
+		}
+		buf.WriteByte('\r')
+		if x > 0 {
+			buf.WriteString(ansi.CursorForward(x))
+		}
+	}
+	r.cursorPlaced = true
+
+	if style := c.style(); style != r.cursorStyle {
+		r.cursorStyle = style
+		buf.WriteString(ansi.SetCursorStyle(style))
+	}
+	if !r.cursorShown {
+		r.cursorShown = true
+		buf.WriteString(ansi.ShowCursor)
+	}
+}
+
+// parkCursor moves the cursor placed by the model back to the start of the
+// last line.
+func (r *standardRenderer) parkCursor(buf *bytes.Buffer) {
+	if !r.cursorPlaced {
+		return
+	}
+	r.cursorPlaced = false
+
+	if r.altScreenActive {
+		buf.WriteString(ansi.CursorPosition(0, r.altLinesRendered))
+		return
+	}
+	buf.WriteByte('\r')
+	if r.cursorUp > 0 {
+		buf.WriteString(ansi.CursorDown(r.cursorUp))
+	}
+}
+
+// resetCursor parks the cursor and restores its style, before the renderer
+// stops.
+func (r *standardRenderer) resetCursor() {
+	buf := &bytes.Buffer{}
+	r.parkCursor(buf)
+	if r.cursorStyle != 0 {
+		r.cursorStyle = 0
+		buf.WriteString(ansi.SetCursorStyle(0))
+	}
+	if buf.Len() > 0 {
+		r.execute(buf.String())
+	}
+}
+
+// WriteSequence writes an escape sequence to the terminal between frames.
+func (r *standardRenderer) WriteSequence(seq string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.execute(seq)
+}
+
+// Kill halts the renderer. The final frame will not be rendered.
+func (r *standardRenderer) Kill() {
 	// Stop the renderer before acquiring the mutex to avoid a deadlock.
 	r.once.Do(func() {
 		r.done <- struct{}{}
@@ -138,6 +275,7 @@ func (r *standardRenderer) kill() {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
+	r.resetCursor()
 	r.execute(ansi.EraseEntireLine)
 	// Move the cursor back to the beginning of the line
 	r.execute("\r")
@@ -163,19 +301,22 @@ func (r *standardRenderer) flush() {
 	defer r.mtx.Unlock()
 
 	if r.buf.Len() == 0 || r.buf.String() == r.lastRender {
-		// Nothing to do.
+		// Nothing to do, unless the model moved the cursor.
+		if r.cursorDirty && r.lastRender != "" {
+			buf := &bytes.Buffer{}
+			r.parkCursor(buf)
+			r.placeCursor(buf, len(r.lastRenderedLines))
+			r.writeFrame(buf.Bytes())
+		}
 		return
 	}
 
 	// Output buffer.
 	buf := &bytes.Buffer{}
 
-	// Moving to the beginning of the section, that we rendered.
-	if r.altScreenActive {
-		buf.WriteString(ansi.CursorHomePosition)
-	} else if r.linesRendered > 1 {
-		buf.WriteString(ansi.CursorUp(r.linesRendered - 1))
-	}
+	// Move the cursor back to the start of the last line, where rendering
+	// expects it.
+	r.parkCursor(buf)
 
 	newLines := strings.Split(r.buf.String(), "\n")
 
@@ -183,12 +324,35 @@ func (r *standardRenderer) flush() {
 	// lines we can render. We drop lines from the top of the render buffer if
 	// necessary, as we can't navigate the cursor into the terminal's scrollback
 	// buffer.
+	r.linesDropped = 0
 	if r.height > 0 && len(newLines) > r.height {
-		newLines = newLines[len(newLines)-r.height:]
+		r.linesDropped = len(newLines) - r.height
+		newLines = newLines[r.linesDropped:]
 	}
 
 	flushQueuedMessages := len(r.queuedMessageLines) > 0 && !r.altScreenActive
 
+	if r.cellDiff && r.lastCells != nil && !flushQueuedMessages && len(r.ignoreLines) == 0 {
+		// Only write the cells that changed since the last frame.
+		r.flushCells(buf, newLines)
+		r.setLinesRendered(len(newLines))
+		r.finishFlush(buf, newLines)
+		return
+	}
+
+	// In cell mode, the terminal's pen is tracked so each line is drawn with
+	// the style the lines above it leave open, even when those are skipped,
+	// and the next frame can be diffed from a reset pen.
+	var term, frame cellParser
+	cells := make([][]cell, len(newLines))
+
+	// Moving to the beginning of the section, that we rendered.
+	if r.altScreenActive {
+		buf.WriteString(ansi.CursorHomePosition)
+	} else if r.linesRendered > 1 {
+		buf.WriteString(ansi.CursorUp(r.linesRendered - 1))
+	}
+
 	if flushQueuedMessages {
 		// Dump the lines we've queued up for printing.
 		for _, line := range r.queuedMessageLines {
@@ -204,6 +368,9 @@ func (r *standardRenderer) flush() {
 
 			_, _ = buf.WriteString(line)
 			_, _ = buf.WriteString("\r\n")
+			if r.cellDiff {
+				term.parse(line, 0)
+			}
 		}
 		// Clear the queued message lines.
 		r.queuedMessageLines = []string{}
@@ -211,6 +378,11 @@ func (r *standardRenderer) flush() {
 
 	// Paint new lines.
 	for i := 0; i < len(newLines); i++ {
+		start := frame.pen
+		if r.cellDiff {
+			cells[i] = frame.parse(newLines[i], r.width)
+		}
+
 		canSkip := !flushQueuedMessages && // Queuing messages triggers repaint -> we don't have access to previous frame content.
 			len(r.lastRenderedLines) > i && r.lastRenderedLines[i] == newLines[i] // Previously rendered line is the same.
 
@@ -251,23 +423,27 @@ func (r *standardRenderer) flush() {
 			line = line + ansi.EraseLineRight
 		}
 
+		if r.cellDiff {
+			term.set(buf, start.style, start.link)
+			term.parse(line, 0)
+		}
+
 		_, _ = buf.WriteString(line)
 
 		if i < len(newLines)-1 {
 			_, _ = buf.WriteString("\r\n")
 		}
 	}
+	if r.cellDiff {
+		term.set(buf, vt.Style{}, "")
+	}
 
 	// Clearing left over content from last render.
 	if r.lastLinesRendered() > len(newLines) {
 		buf.WriteString(ansi.EraseScreenBelow)
 	}
 
-	if r.altScreenActive {
-		r.altLinesRendered = len(newLines)
-	} else {
-		r.linesRendered = len(newLines)
-	}
+	r.setLinesRendered(len(newLines))
 
 	// Make sure the cursor is at the start of the last line to keep rendering
 	// behavior consistent.
@@ -280,7 +456,19 @@ func (r *standardRenderer) flush() {
 		buf.WriteString(ansi.CursorBackward(r.width))
 	}
 
-	_, _ = r.out.Write(buf.Bytes())
+	if r.cellDiff {
+		// Keep the cells of this frame to diff the next one against.
+		r.lastCells = cells
+	}
+
+	r.finishFlush(buf, newLines)
+}
+
+// finishFlush writes a rendered frame to the output and saves it for
+// comparison in the next render.
+func (r *standardRenderer) finishFlush(buf *bytes.Buffer, newLines []string) {
+	r.placeCursor(buf, len(newLines))
+	r.writeFrame(buf.Bytes())
 	r.lastRender = r.buf.String()
 
 	// Save previously rendered lines for comparison in the next render. If we
@@ -290,6 +478,36 @@ func (r *standardRenderer) flush() {
 	r.buf.Reset()
 }
 
+// writeFrame writes a frame to the output. When synchronized output is
+// enabled the frame is written as a synchronized update, so the terminal
+// never shows it half drawn.
+func (r *standardRenderer) writeFrame(frame []byte) {
+	if r.syncOutput {
+		b := make([]byte, 0, len(ansi.SetSynchronizedOutputMode)+len(frame)+len(ansi.ResetSynchronizedOutputMode))
+		b = append(b, ansi.SetSynchronizedOutputMode...)
+		b = append(b, frame...)
+		frame = append(b, ansi.ResetSynchronizedOutputMode...)
+	}
+	_, _ = r.out.Write(frame)
+}
+
+// SetSynchronizedOutput sets whether frames are written as synchronized
+// updates (mode 2026).
+func (r *standardRenderer) SetSynchronizedOutput(enabled bool) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.syncOutput = enabled
+}
+
+// setLinesRendered sets the number of lines rendered on the current screen.
+func (r *standardRenderer) setLinesRendered(n int) {
+	if r.altScreenActive {
+		r.altLinesRendered = n
+	} else {
+		r.linesRendered = n
+	}
+}
+
 // lastLinesRendered returns the number of lines rendered lastly.
 func (r *standardRenderer) lastLinesRendered() int {
 	if r.altScreenActive {
@@ -298,9 +516,9 @@ func (r *standardRenderer) lastLinesRendered() int {
 	return r.linesRendered
 }
 
-// write writes to the internal buffer. The buffer will be outputted via the
+// Write writes to the internal buffer. The buffer will be outputted via the
 // ticker which calls flush().
-func (r *standardRenderer) write(s string) {
+func (r *standardRenderer) Write(s string) {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 	r.buf.Reset()
@@ -316,29 +534,32 @@ func (r *standardRenderer) write(s string) {
 	_, _ = r.buf.WriteString(s)
 }
 
-func (r *standardRenderer) repaint() {
+func (r *standardRenderer) Repaint() {
 	r.lastRender = ""
 	r.lastRenderedLines = nil
+	r.lastCells = nil
 }
 
-func (r *standardRenderer) clearScreen() {
+func (r *standardRenderer) ClearScreen() {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
 	r.execute(ansi.EraseEntireScreen)
 	r.execute(ansi.CursorHomePosition)
+	r.cursorPlaced = false
+	r.cursorDirty = true
 
-	r.repaint()
+	r.Repaint()
 }
 
-func (r *standardRenderer) altScreen() bool {
+func (r *standardRenderer) AltScreen() bool {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
 	return r.altScreenActive
 }
 
-func (r *standardRenderer) enterAltScreen() {
+func (r *standardRenderer) EnterAltScreen() {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
@@ -346,6 +567,14 @@ func (r *standardRenderer) enterAltScreen() {
 		return
 	}
 
+	// Park the cursor so it's restored to the start of the last line when
+	// exiting the alt screen.
+	buf := &bytes.Buffer{}
+	r.parkCursor(buf)
+	if buf.Len() > 0 {
+		r.execute(buf.String())
+	}
+
 	r.altScreenActive = true
 	r.execute(ansi.SetAltScreenSaveCursorMode)
 
@@ -353,7 +582,7 @@ func (r *standardRenderer) enterAltScreen() {
 	// alt screen (or alt screen support is disabled, like GNU screen by
 	// default).
 	//
-	// Note: we can't use r.clearScreen() here because the mutex is already
+	// Note: we can't use r.ClearScreen() here because the mutex is already
 	// locked.
 	r.execute(ansi.EraseEntireScreen)
 	r.execute(ansi.CursorHomePosition)
@@ -367,13 +596,16 @@ func (r *standardRenderer) enterAltScreen() {
 		r.execute(ansi.ShowCursor)
 	}
 
+	r.cursorShown = false
+	r.cursorDirty = true
+
 	// Entering the alt screen resets the lines rendered count.
 	r.altLinesRendered = 0
 
-	r.repaint()
+	r.Repaint()
 }
 
-func (r *standardRenderer) exitAltScreen() {
+func (r *standardRenderer) ExitAltScreen() {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
@@ -393,10 +625,16 @@ func (r *standardRenderer) exitAltScreen() {
 		r.execute(ansi.ShowCursor)
 	}
 
-	r.repaint()
+	// The cursor is restored to where it was parked before entering the alt
+	// screen.
+	r.cursorPlaced = false
+	r.cursorShown = false
+	r.cursorDirty = true
+
+	r.Repaint()
 }
 
-func (r *standardRenderer) showCursor() {
+func (r *standardRenderer) ShowCursor() {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
@@ -404,7 +642,7 @@ func (r *standardRenderer) showCursor() {
 	r.execute(ansi.ShowCursor)
 }
 
-func (r *standardRenderer) hideCursor() {
+func (r *standardRenderer) HideCursor() {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
@@ -412,49 +650,49 @@ func (r *standardRenderer) hideCursor() {
 	r.execute(ansi.HideCursor)
 }
 
-func (r *standardRenderer) enableMouseCellMotion() {
+func (r *standardRenderer) EnableMouseCellMotion() {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
 	r.execute(ansi.SetButtonEventMouseMode)
 }
 
-func (r *standardRenderer) disableMouseCellMotion() {
+func (r *standardRenderer) DisableMouseCellMotion() {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
 	r.execute(ansi.ResetButtonEventMouseMode)
 }
 
-func (r *standardRenderer) enableMouseAllMotion() {
+func (r *standardRenderer) EnableMouseAllMotion() {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
 	r.execute(ansi.SetAnyEventMouseMode)
 }
 
-func (r *standardRenderer) disableMouseAllMotion() {
+func (r *standardRenderer) DisableMouseAllMotion() {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
 	r.execute(ansi.ResetAnyEventMouseMode)
 }
 
-func (r *standardRenderer) enableMouseSGRMode() {
+func (r *standardRenderer) EnableMouseSGRMode() {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
 	r.execute(ansi.SetSgrExtMouseMode)
 }
 
-func (r *standardRenderer) disableMouseSGRMode() {
+func (r *standardRenderer) DisableMouseSGRMode() {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
 	r.execute(ansi.ResetSgrExtMouseMode)
 }
 
-func (r *standardRenderer) enableBracketedPaste() {
+func (r *standardRenderer) EnableBracketedPaste() {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
@@ -462,7 +700,7 @@ func (r *standardRenderer) enableBracketedPaste() {
 	r.bpActive = true
 }
 
-func (r *standardRenderer) disableBracketedPaste() {
+func (r *standardRenderer) DisableBracketedPaste() {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
@@ -470,14 +708,14 @@ func (r *standardRenderer) disableBracketedPaste() {
 	r.bpActive = false
 }
 
-func (r *standardRenderer) bracketedPasteActive() bool {
+func (r *standardRenderer) BracketedPasteActive() bool {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
 	return r.bpActive
 }
 
-func (r *standardRenderer) enableReportFocus() {
+func (r *standardRenderer) EnableReportFocus() {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
@@ -485,7 +723,7 @@ func (r *standardRenderer) enableReportFocus() {
 	r.reportingFocus = true
 }
 
-func (r *standardRenderer) disableReportFocus() {
+func (r *standardRenderer) DisableReportFocus() {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
@@ -493,15 +731,69 @@ func (r *standardRenderer) disableReportFocus() {
 	r.reportingFocus = false
 }
 
-func (r *standardRenderer) reportFocus() bool {
+func (r *standardRenderer) ReportFocus() bool {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
 	return r.reportingFocus
 }
 
-// setWindowTitle sets the terminal window title.
-func (r *standardRenderer) setWindowTitle(title string) {
+func (r *standardRenderer) KeyboardEnhancements() KeyboardEnhancements {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	return r.enhancements
+}
+
+func (r *standardRenderer) EnableKeyboardEnhancements(e KeyboardEnhancements) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	// Replace rather than stack the enhancements if they're already active
+	// so a single pop restores the terminal.
+	if r.enhancements != 0 {
+		r.execute(ansi.PopKittyKeyboard(1))
+	}
+	r.execute(ansi.PushKittyKeyboard(int(e)))
+	r.enhancements = e
+}
+
+func (r *standardRenderer) DisableKeyboardEnhancements() {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.enhancements == 0 {
+		return
+	}
+	r.execute(ansi.PopKittyKeyboard(1))
+	r.enhancements = 0
+}
+
+func (r *standardRenderer) ModifyOtherKeys() bool {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	return r.modifyOtherKeys
+}
+
+func (r *standardRenderer) EnableModifyOtherKeys() {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.execute(ansi.SetModifyOtherKeys2)
+	r.modifyOtherKeys = true
+}
+
+func (r *standardRenderer) DisableModifyOtherKeys() {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.execute(ansi.ResetModifyOtherKeys)
+	r.modifyOtherKeys = false
+}
+
+// SetWindowTitle sets the terminal window title.
+func (r *standardRenderer) SetWindowTitle(title string) {
 	r.execute(ansi.SetWindowTitle(title))
 }
 
@@ -613,21 +905,21 @@ func (r *standardRenderer) insertBottom(lines []string, topBoundary, bottomBound
 	_, _ = r.out.Write(buf.Bytes())
 }
 
-// handleMessages handles internal messages for the renderer.
-func (r *standardRenderer) handleMessages(msg Msg) {
+// HandleMessage handles internal messages for the renderer.
+func (r *standardRenderer) HandleMessage(msg Msg) {
 	switch msg := msg.(type) {
 	case repaintMsg:
 		// Force a repaint by clearing the render cache as we slide into a
 		// render.
 		r.mtx.Lock()
-		r.repaint()
+		r.Repaint()
 		r.mtx.Unlock()
 
 	case WindowSizeMsg:
 		r.mtx.Lock()
 		r.width = msg.Width
 		r.height = msg.Height
-		r.repaint()
+		r.Repaint()
 		r.mtx.Unlock()
 
 	case clearScrollAreaMsg:
@@ -636,7 +928,7 @@ func (r *standardRenderer) handleMessages(msg Msg) {
 		// Force a repaint on the area where the scrollable stuff was in this
 		// update cycle
 		r.mtx.Lock()
-		r.repaint()
+		r.Repaint()
 		r.mtx.Unlock()
 
 	case syncScrollAreaMsg:
@@ -647,7 +939,7 @@ func (r *standardRenderer) handleMessages(msg Msg) {
 
 		// Force non-scrolling stuff to repaint in this update cycle
 		r.mtx.Lock()
-		r.repaint()
+		r.Repaint()
 		r.mtx.Unlock()
 
 	case scrollUpMsg:
@@ -661,7 +953,7 @@ func (r *standardRenderer) handleMessages(msg Msg) {
 			lines := strings.Split(msg.messageBody, "\n")
 			r.mtx.Lock()
 			r.queuedMessageLines = append(r.queuedMessageLines, lines...)
-			r.repaint()
+			r.Repaint()
 			r.mtx.Unlock()
 		}
 	}
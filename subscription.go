@@ -0,0 +1,158 @@
+package tea
+
+import (
+	"context"
+	"time"
+)
+
+// Sub is a subscription to a long-running source of messages, such as a
+// ticker, a channel or a file watcher, see [SubscriptionModel].
+type Sub struct {
+	// ID identifies the subscription. Subscriptions with the same ID are
+	// the same subscription, so to change the parameters of a subscription,
+	// change its ID too.
+	ID string
+
+	// Run runs the subscription, sending its messages to the program with
+	// send until ctx is cancelled, when the subscription is stopped.
+	Run func(ctx context.Context, send func(Msg))
+}
+
+// SubscriptionModel is a model that subscribes to long-running sources of
+// messages, rather than re-arming commands from every call to Update.
+//
+// Subscriptions is called after Init and every call to Update. Subscriptions
+// it returns that aren't running yet are started, and running subscriptions
+// it no longer returns are stopped, so the sources of messages follow the
+// state of the model:
+//
+//	func (m model) Subscriptions() []tea.Sub {
+//		if m.paused {
+//			return nil
+//		}
+//		return []tea.Sub{
+//			tea.EverySub("clock", time.Second, func(t time.Time) tea.Msg {
+//				return tickMsg(t)
+//			}),
+//		}
+//	}
+//
+// As it's called often, Subscriptions should be cheap.
+type SubscriptionModel interface {
+	Model
+
+	// Subscriptions returns the subscriptions that should be running.
+	Subscriptions() []Sub
+}
+
+// EverySub returns a subscription that sends the message fn returns every
+// interval, in sync with the system clock, like [Every].
+func EverySub(id string, interval time.Duration, fn func(time.Time) Msg) Sub {
+	return Sub{
+		ID: id,
+		Run: func(ctx context.Context, send func(Msg)) {
+			for {
+				n := time.Now()
+				d := n.Truncate(interval).Add(interval).Sub(n)
+				t := time.NewTimer(d)
+				select {
+				case <-ctx.Done():
+					t.Stop()
+					return
+				case now := <-t.C:
+					send(fn(now))
+				}
+			}
+		},
+	}
+}
+
+// ChannelSub returns a subscription that sends the message fn returns for
+// every value received from ch, until ch is closed.
+func ChannelSub[T any](id string, ch <-chan T, fn func(T) Msg) Sub {
+	return Sub{
+		ID: id,
+		Run: func(ctx context.Context, send func(Msg)) {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case v, ok := <-ch:
+					if !ok {
+						return
+					}
+					send(fn(v))
+				}
+			}
+		},
+	}
+}
+
+// subMsg is an internal message with a message of a subscription.
+type subMsg struct {
+	id  string
+	sub *runningSub
+	msg Msg
+}
+
+// runningSub is a running subscription.
+type runningSub struct {
+	cancel context.CancelFunc
+}
+
+// updateSubscriptions starts the subscriptions of the model that aren't
+// running yet, and stops those it no longer returns.
+func (p *Program) updateSubscriptions(model Model) {
+	m, ok := model.(SubscriptionModel)
+	if !ok {
+		return
+	}
+	subs := m.Subscriptions()
+
+	wanted := make(map[string]struct{}, len(subs))
+	for _, sub := range subs {
+		wanted[sub.ID] = struct{}{}
+	}
+	for id, running := range p.subs {
+		if _, ok := wanted[id]; !ok {
+			running.cancel()
+			delete(p.subs, id)
+		}
+	}
+
+	for _, sub := range subs {
+		if _, ok := p.subs[sub.ID]; ok || sub.Run == nil {
+			continue
+		}
+		if p.subs == nil {
+			p.subs = map[string]*runningSub{}
+		}
+
+		ctx, cancel := context.WithCancel(p.ctx)
+		running := &runningSub{cancel: cancel}
+		p.subs[sub.ID] = running
+
+		id, run := sub.ID, sub.Run
+		p.goContext(func() {
+			run(ctx, func(msg Msg) {
+				select {
+				case <-ctx.Done():
+				case p.msgs <- subMsg{id: id, sub: running, msg: msg}:
+				}
+			})
+		})
+	}
+}
+
+// resolveSubscription returns the message of a subscription, or nil if the
+// subscription was stopped.
+func (p *Program) resolveSubscription(msg Msg) Msg {
+	sm, ok := msg.(subMsg)
+	if !ok {
+		return msg
+	}
+	if p.subs[sm.id] != sm.sub {
+		return nil
+	}
+	return sm.msg
+}
@@ -0,0 +1,89 @@
+package tea
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type subModel struct {
+	numbers  chan int
+	stopped  *atomic.Bool
+	received []int
+	ticked   bool
+}
+
+func (m subModel) Init() Cmd {
+	return nil
+}
+
+func (m subModel) Update(msg Msg) (Model, Cmd) {
+	switch msg := msg.(type) {
+	case int:
+		m.received = append(m.received, msg)
+	case time.Time:
+		m.ticked = true
+		return m, Quit
+	}
+	return m, nil
+}
+
+func (m subModel) View() string {
+	return ""
+}
+
+func (m subModel) Subscriptions() []Sub {
+	numbers := ChannelSub("numbers", m.numbers, func(n int) Msg { return n })
+	if len(m.received) < 2 {
+		// Listed twice, started once.
+		return []Sub{numbers, numbers}
+	}
+	return []Sub{
+		{
+			ID: "stopped",
+			Run: func(ctx context.Context, _ func(Msg)) {
+				<-ctx.Done()
+				m.stopped.Store(true)
+			},
+		},
+		EverySub("tick", time.Millisecond, func(t time.Time) Msg { return t }),
+	}
+}
+
+func TestSubscriptions(t *testing.T) {
+	numbers := make(chan int)
+	m := subModel{numbers: numbers, stopped: &atomic.Bool{}}
+
+	go func() {
+		// Once the model has 2 numbers the subscription is stopped, and
+		// the channel is no longer read.
+		for i := 1; ; i++ {
+			select {
+			case numbers <- i:
+			case <-time.After(time.Second):
+				return
+			}
+		}
+	}()
+
+	var buf, in bytes.Buffer
+	p := NewProgram(m, WithInput(&in), WithOutput(&buf), WithShutdownTimeout(time.Second))
+	final, err := p.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := final.(subModel)
+	if want := []int{1, 2}; !reflect.DeepEqual(got.received, want) {
+		t.Errorf("expected numbers %v, got %v", want, got.received)
+	}
+	if !got.ticked {
+		t.Error("expected the tick subscription to start")
+	}
+	if !m.stopped.Load() {
+		t.Error("expected subscriptions to be stopped on exit")
+	}
+}
@@ -0,0 +1,53 @@
+package tea
+
+import "github.com/charmbracelet/x/ansi"
+
+// initSyncOutput enables synchronized output as requested, or asks the
+// terminal whether it supports it.
+func (p *Program) initSyncOutput() {
+	r, ok := p.renderer.(syncOutputSetter)
+	if !ok {
+		return
+	}
+
+	switch {
+	case p.startupOptions.has(withSynchronizedOutput):
+		r.SetSynchronizedOutput(true)
+	case p.startupOptions.has(withoutSynchronizedOutput):
+	case p.ttyOutput != nil && p.ttyInput != nil:
+		// The reply comes in as input, so only ask terminals we read from.
+		// Terminals reply in order, so the reply to the device attributes
+		// tells when one that ignores the mode request won't report it.
+		p.detectingSyncOutput = true
+		p.syncOutputSentinel = true
+		p.execute(ansi.RequestSynchronizedOutputMode + ansi.RequestPrimaryDeviceAttributes)
+	}
+}
+
+// detectSyncOutput enables synchronized output when the terminal reports
+// supporting it. It returns nil for the replies to the program's own queries,
+// which are for the program rather than the model.
+func (p *Program) detectSyncOutput(msg Msg) Msg {
+	switch msg := msg.(type) {
+	case ModeReportMsg:
+		if !p.detectingSyncOutput || msg.Mode != ansi.SynchronizedOutputMode.Mode() {
+			return msg
+		}
+		p.detectingSyncOutput = false
+
+		// Terminals that support the mode report it as either set or reset.
+		if r, ok := p.renderer.(syncOutputSetter); ok && !msg.Setting.IsNotRecognized() {
+			r.SetSynchronizedOutput(true)
+		}
+		return nil
+
+	case PrimaryDeviceAttributesMsg:
+		if !p.syncOutputSentinel {
+			return msg
+		}
+		// Later mode reports are the model's.
+		p.detectingSyncOutput, p.syncOutputSentinel = false, false
+		return nil
+	}
+	return msg
+}
@@ -0,0 +1,131 @@
+package tea
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestStandardRendererSynchronizedOutput(t *testing.T) {
+	for _, alt := range []bool{false, true} {
+		name := "inline"
+		if alt {
+			name = "altscreen"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			r := newRenderer(&buf, false, 60, false).(*standardRenderer)
+			r.HandleMessage(WindowSizeMsg{Width: 20, Height: 5})
+			if alt {
+				r.EnterAltScreen()
+			}
+			r.SetSynchronizedOutput(true)
+
+			expectFrame := func(contains string) {
+				t.Helper()
+				out := buf.String()
+				if !strings.HasPrefix(out, ansi.SetSynchronizedOutputMode) || !strings.HasSuffix(out, ansi.ResetSynchronizedOutputMode) {
+					t.Errorf("expected a synchronized update, got %q", out)
+				}
+				if !strings.Contains(out, contains) {
+					t.Errorf("expected %q in the frame, got %q", contains, out)
+				}
+				buf.Reset()
+			}
+
+			buf.Reset()
+			r.Write("hello\nworld")
+			r.flush()
+			expectFrame("world")
+
+			// Printed lines are flushed with the frame.
+			r.HandleMessage(printLineMessage{messageBody: "printed"})
+			r.Write("hello\nthere")
+			r.flush()
+			if alt {
+				expectFrame("there")
+			} else {
+				expectFrame("printed")
+			}
+
+			// So are cursor movements on their own.
+			r.SetCursor(&Cursor{X: 2})
+			r.flush()
+			expectFrame(ansi.ShowCursor)
+
+			r.SetSynchronizedOutput(false)
+			r.Write("bye")
+			r.flush()
+			if strings.Contains(buf.String(), ansi.SetSynchronizedOutputMode) {
+				t.Errorf("expected no synchronized update, got %q", buf.String())
+			}
+		})
+	}
+}
+
+func TestDetectSyncOutput(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRenderer(&buf, false, 60, false).(*standardRenderer)
+	p := &Program{renderer: r, detectingSyncOutput: true}
+
+	other := ModeReportMsg{Mode: 2004, Setting: ansi.ModeSet}
+	if msg := p.detectSyncOutput(other); msg != other {
+		t.Errorf("expected other reports to reach the model, got %#v", msg)
+	}
+
+	if msg := p.detectSyncOutput(ModeReportMsg{Mode: 2026, Setting: ansi.ModeReset}); msg != nil {
+		t.Errorf("expected the report not to reach the model, got %#v", msg)
+	}
+	if !r.syncOutput {
+		t.Error("expected synchronized output to be enabled")
+	}
+
+	// Only the reply to the program's own query is taken.
+	report := ModeReportMsg{Mode: 2026, Setting: ansi.ModeSet}
+	if msg := p.detectSyncOutput(report); msg != report {
+		t.Errorf("expected later reports to reach the model, got %#v", msg)
+	}
+
+	r = newRenderer(&buf, false, 60, false).(*standardRenderer)
+	p = &Program{renderer: r, detectingSyncOutput: true}
+	p.detectSyncOutput(ModeReportMsg{Mode: 2026, Setting: ansi.ModeNotRecognized})
+	if r.syncOutput {
+		t.Error("expected synchronized output to stay disabled")
+	}
+
+	// Terminals that ignore the mode request still reply to the device
+	// attributes requested after it, which ends the detection.
+	r = newRenderer(&buf, false, 60, false).(*standardRenderer)
+	p = &Program{renderer: r, detectingSyncOutput: true, syncOutputSentinel: true}
+	if msg := p.detectSyncOutput(PrimaryDeviceAttributesMsg{62, 22}); msg != nil {
+		t.Errorf("expected the device attributes not to reach the model, got %#v", msg)
+	}
+	if msg := p.detectSyncOutput(report); msg != report {
+		t.Errorf("expected reports after the device attributes to reach the model, got %#v", msg)
+	}
+	if r.syncOutput {
+		t.Error("expected synchronized output to stay disabled")
+	}
+	attrs := PrimaryDeviceAttributesMsg{62, 22}
+	if msg := p.detectSyncOutput(attrs); !reflect.DeepEqual(msg, attrs) {
+		t.Errorf("expected later device attributes to reach the model, got %#v", msg)
+	}
+}
+
+func TestProgramSynchronizedOutput(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+	m := &testModel{}
+	p := NewProgram(m, WithInput(&in), WithOutput(&buf), WithSynchronizedOutput())
+	go p.Send(QuitMsg{})
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), ansi.SetSynchronizedOutputMode+"\rsuccess") {
+		t.Errorf("expected the frame in a synchronized update, got %q", buf.String())
+	}
+}
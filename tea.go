@@ -14,14 +14,19 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"os/signal"
 	"runtime"
 	"runtime/debug"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"syscall"
+	"time"
 
+	"github.com/charmbracelet/x/ansi"
 	"github.com/charmbracelet/x/term"
 	"github.com/muesli/cancelreader"
 	"golang.org/x/sync/errgroup"
@@ -106,6 +111,13 @@ const (
 	withoutCatchPanics
 	withoutBracketedPaste
 	withReportFocus
+	withCellRenderer
+	withSynchronousCommands
+	withSynchronizedOutput
+	withoutSynchronizedOutput
+	withPanicMsgs
+	withErrorMsgs
+	withModifyOtherKeys
 )
 
 // channelHandlers manages the series of channels returned by various processes.
@@ -148,6 +160,10 @@ type Program struct {
 	// program starts.
 	startupTitle string
 
+	// startupWidth and startupHeight are the size of the window when the
+	// program starts, if set with WithWindowSize.
+	startupWidth, startupHeight int
+
 	inputType inputType
 
 	// externalCtx is a context that was passed in via WithContext, otherwise defaulting
@@ -163,12 +179,16 @@ type Program struct {
 	errs     chan error
 	finished chan struct{}
 
+	// queue holds the messages of commands run synchronously, waiting to be
+	// processed by the event loop.
+	queue []Msg
+
 	// where to send output, this will usually be os.Stdout.
 	output io.Writer
 	// ttyOutput is null if output is not a TTY.
 	ttyOutput           term.File
 	previousOutputState *term.State
-	renderer            renderer
+	renderer            Renderer
 
 	// the environment variables for the program, defaults to os.Environ().
 	environ []string
@@ -188,7 +208,12 @@ type Program struct {
 	bpWasActive bool // was the bracketed paste mode active before releasing the terminal?
 	reportFocus bool // was focus reporting active before releasing the terminal?
 
-	filter func(Model, Msg) Msg
+	// keyboardEnhancements are the kitty keyboard protocol enhancements
+	// requested with WithKeyboardEnhancements.
+	keyboardEnhancements KeyboardEnhancements
+
+	// middleware wraps the update of the model, see WithMiddleware.
+	middleware []Middleware
 
 	// fps is the frames per second we should set on the renderer, if
 	// applicable,
@@ -196,6 +221,55 @@ type Program struct {
 
 	// mouseMode is true if the program should enable mouse mode on Windows.
 	mouseMode bool
+
+	// keySequences are the key sequences recognized on top of the built-in
+	// ones, see WithKeySequences, along with those of the terminfo entry of
+	// the terminal if loadTerminfo is set, see WithTerminfoKeys. parser is
+	// the parser of the input recognizing them.
+	keySequences map[string]Key
+	loadTerminfo bool
+	parser       *inputParser
+
+	// escTimeout is how long a trailing escape character is held back
+	// waiting for the rest of an escape sequence, see WithEscapeTimeout.
+	escTimeout time.Duration
+
+	// recorder records the session, if set.
+	recorder *recorder
+
+	// tracer writes a trace log of the program, if set.
+	tracer *tracer
+
+	// contextCmds are the running context commands, which are waited for
+	// for up to shutdownTimeout on shutdown.
+	contextCmds     sync.WaitGroup
+	shutdownTimeout time.Duration
+
+	// keyedCmds are the running keyed commands, and throttled the times
+	// throttled commands are throttled until, by key.
+	keyedCmds map[string]*keyedCmd
+	throttled map[string]time.Time
+
+	// subs are the running subscriptions of the model, by ID.
+	subs map[string]*runningSub
+
+	// replayEvents are input events to replay, see WithReplay.
+	replayEvents []ReplayEvent
+
+	// queries are the terminal queries waiting for replies, oldest first.
+	queries []*pendingQuery
+
+	// cursorRequests is the number of cursor position reports requested
+	// but not received yet.
+	cursorRequests int
+
+	// detectingSyncOutput is whether the program is waiting for the terminal
+	// to report whether it supports synchronized output, and
+	// syncOutputSentinel whether it's waiting for the reply to the device
+	// attributes requested after it, which terminals that ignore the mode
+	// request still send.
+	detectingSyncOutput bool
+	syncOutputSentinel  bool
 }
 
 // Quit is a special command that tells the Bubble Tea program to exit.
@@ -268,9 +342,29 @@ func NewProgram(model Model, opts ...ProgramOption) *Program {
 		p.environ = os.Environ()
 	}
 
+	seqs := p.keySequences
+	if p.loadTerminfo {
+		seqs = loadTerminfoKeys(p.getenv)
+		if seqs == nil {
+			seqs = map[string]Key{}
+		}
+		maps.Copy(seqs, p.keySequences)
+	}
+	p.parser = newInputParser(seqs)
+
 	return p
 }
 
+// getenv returns the value of an environment variable of the program.
+func (p *Program) getenv(key string) string {
+	for _, kv := range p.environ {
+		if k, v, ok := strings.Cut(kv, "="); ok && k == key {
+			return v
+		}
+	}
+	return ""
+}
+
 func (p *Program) handleSignals() chan struct{} {
 	ch := make(chan struct{})
 
@@ -316,9 +410,15 @@ func (p *Program) handleSignals() chan struct{} {
 func (p *Program) handleResize() chan struct{} {
 	ch := make(chan struct{})
 
-	if p.ttyOutput != nil {
+	if p.startupWidth > 0 && p.startupHeight > 0 {
+		// Send the size set with WithWindowSize instead of the terminal's.
+		go p.Resize(p.startupWidth, p.startupHeight)
+	} else if p.ttyOutput != nil {
 		// Get the initial terminal size and send it to the program.
 		go p.checkResize()
+	}
+
+	if p.ttyOutput != nil {
 
 		// Listen for window resizes.
 		go p.listenForResize(ch)
@@ -353,14 +453,7 @@ func (p *Program) handleCommands(cmds chan Cmd) chan struct{} {
 				// possible to cancel them so we'll have to leak the goroutine
 				// until Cmd returns.
 				go func() {
-					// Recover from panics.
-					if !p.startupOptions.has(withoutCatchPanics) {
-						defer func() {
-							if r := recover(); r != nil {
-								p.recoverFromGoPanic(r)
-							}
-						}()
-					}
+					defer p.catchCmdPanic()
 
 					msg := cmd() // this can be long.
 					p.Send(msg)
@@ -373,166 +466,433 @@ func (p *Program) handleCommands(cmds chan Cmd) chan struct{} {
 }
 
 func (p *Program) disableMouse() {
-	p.renderer.disableMouseCellMotion()
-	p.renderer.disableMouseAllMotion()
-	p.renderer.disableMouseSGRMode()
+	p.renderer.DisableMouseCellMotion()
+	p.renderer.DisableMouseAllMotion()
+	p.renderer.DisableMouseSGRMode()
+}
+
+// enableKeyboardEnhancements enables the keyboard protocols requested with
+// WithKeyboardEnhancements and WithModifyOtherKeys, if the renderer supports
+// them.
+func (p *Program) enableKeyboardEnhancements() {
+	r, ok := p.renderer.(keyboardEnhancer)
+	if !ok {
+		return
+	}
+	if p.keyboardEnhancements != 0 {
+		r.EnableKeyboardEnhancements(p.keyboardEnhancements)
+	}
+	if p.startupOptions.has(withModifyOtherKeys) {
+		r.EnableModifyOtherKeys()
+	}
+}
+
+// disableKeyboardEnhancements disables the keyboard protocols enabled by the
+// renderer, if any.
+func (p *Program) disableKeyboardEnhancements() {
+	r, ok := p.renderer.(keyboardEnhancer)
+	if !ok {
+		return
+	}
+	if r.KeyboardEnhancements() != 0 {
+		r.DisableKeyboardEnhancements()
+	}
+	if r.ModifyOtherKeys() {
+		r.DisableModifyOtherKeys()
+	}
 }
 
 // eventLoop is the central message loop. It receives and handles the default
 // Bubble Tea messages, update the model and triggers redraws.
 func (p *Program) eventLoop(model Model, cmds chan Cmd) (Model, error) {
-	for {
+	// Messages of commands run synchronously are queued in front of the
+	// messages that were already queued, so the consequences of a message are
+	// processed before moving on to the next one. next is where the next
+	// message gets queued.
+	var next int
+	enqueue := func(msg Msg) {
+		p.queue = slices.Insert(p.queue, next, msg)
+		next++
+	}
+
+	// dispatch hands a command over to the command handler, or runs it right
+	// away when commands are run synchronously.
+	dispatch := func(cmd Cmd) bool {
+		if cmd == nil {
+			return true
+		}
+		if p.tracer != nil {
+			cmd = p.tracer.cmd(cmd)
+		}
+		if p.startupOptions.has(withSynchronousCommands) {
+			enqueue(p.runCmd(cmd))
+			return true
+		}
 		select {
 		case <-p.ctx.Done():
+			return false
+		case cmds <- cmd:
+			return true
+		}
+	}
+
+	// start runs a command that takes a context, in its own goroutine unless
+	// commands are run synchronously.
+	start := func(cmd Cmd) {
+		if cmd == nil {
+			return
+		}
+		if p.startupOptions.has(withSynchronousCommands) {
+			enqueue(p.runCmd(cmd))
+			return
+		}
+		p.goContextCmd(cmd)
+	}
+
+	// exit and exitErr are set by handle when the program should exit.
+	// skipped is cleared when the message reaches the model, and stays set
+	// when it's dropped by a filter or only hands commands over, like a
+	// batch: the subscriptions aren't followed and the view isn't rendered.
+	var (
+		exit    bool
+		exitErr error
+		skipped bool
+	)
+
+	// handle handles the special internal messages and updates the model.
+	handle := func(model Model, msg Msg) (Model, Cmd) {
+		// Handle special internal messages.
+		switch msg := msg.(type) {
+		case QuitMsg:
+			exit = true
 			return model, nil
 
-		case err := <-p.errs:
-			return model, err
+		case InterruptMsg:
+			exit, exitErr = true, ErrInterrupted
+			return model, nil
 
-		case msg := <-p.msgs:
-			// Filter messages.
-			if p.filter != nil {
-				msg = p.filter(model, msg)
-			}
-			if msg == nil {
-				continue
+		case SuspendMsg:
+			if suspendSupported {
+				p.suspend()
 			}
 
-			// Handle special internal messages.
-			switch msg := msg.(type) {
-			case QuitMsg:
-				return model, nil
-
-			case InterruptMsg:
-				return model, ErrInterrupted
+		case clearScreenMsg:
+			p.renderer.ClearScreen()
 
-			case SuspendMsg:
-				if suspendSupported {
-					p.suspend()
-				}
+		case enterAltScreenMsg:
+			p.renderer.EnterAltScreen()
 
-			case clearScreenMsg:
-				p.renderer.clearScreen()
+		case exitAltScreenMsg:
+			p.renderer.ExitAltScreen()
 
-			case enterAltScreenMsg:
-				p.renderer.enterAltScreen()
+		case enableMouseCellMotionMsg, enableMouseAllMotionMsg:
+			switch msg.(type) {
+			case enableMouseCellMotionMsg:
+				p.renderer.EnableMouseCellMotion()
+			case enableMouseAllMotionMsg:
+				p.renderer.EnableMouseAllMotion()
+			}
+			// mouse mode (1006) is a no-op if the terminal doesn't support it.
+			p.renderer.EnableMouseSGRMode()
+
+			// XXX: This is used to enable mouse mode on Windows. We need
+			// to reinitialize the cancel reader to get the mouse events to
+			// work.
+			if runtime.GOOS == "windows" && !p.mouseMode {
+				p.mouseMode = true
+				p.initCancelReader(true) //nolint:errcheck,gosec
+			}
 
-			case exitAltScreenMsg:
-				p.renderer.exitAltScreen()
+		case disableMouseMsg:
+			p.disableMouse()
 
-			case enableMouseCellMotionMsg, enableMouseAllMotionMsg:
-				switch msg.(type) {
-				case enableMouseCellMotionMsg:
-					p.renderer.enableMouseCellMotion()
-				case enableMouseAllMotionMsg:
-					p.renderer.enableMouseAllMotion()
-				}
-				// mouse mode (1006) is a no-op if the terminal doesn't support it.
-				p.renderer.enableMouseSGRMode()
-
-				// XXX: This is used to enable mouse mode on Windows. We need
-				// to reinitialize the cancel reader to get the mouse events to
-				// work.
-				if runtime.GOOS == "windows" && !p.mouseMode {
-					p.mouseMode = true
-					p.initCancelReader(true) //nolint:errcheck,gosec
-				}
+			// XXX: On Windows, mouse mode is enabled on the input reader
+			// level. We need to instruct the input reader to stop reading
+			// mouse events.
+			if runtime.GOOS == "windows" && p.mouseMode {
+				p.mouseMode = false
+				p.initCancelReader(true) //nolint:errcheck,gosec
+			}
 
-			case disableMouseMsg:
-				p.disableMouse()
+		case showCursorMsg:
+			p.renderer.ShowCursor()
 
-				// XXX: On Windows, mouse mode is enabled on the input reader
-				// level. We need to instruct the input reader to stop reading
-				// mouse events.
-				if runtime.GOOS == "windows" && p.mouseMode {
-					p.mouseMode = false
-					p.initCancelReader(true) //nolint:errcheck,gosec
-				}
+		case hideCursorMsg:
+			p.renderer.HideCursor()
 
-			case showCursorMsg:
-				p.renderer.showCursor()
+		case enableBracketedPasteMsg:
+			p.renderer.EnableBracketedPaste()
 
-			case hideCursorMsg:
-				p.renderer.hideCursor()
+		case disableBracketedPasteMsg:
+			p.renderer.DisableBracketedPaste()
 
-			case enableBracketedPasteMsg:
-				p.renderer.enableBracketedPaste()
+		case enableReportFocusMsg:
+			p.renderer.EnableReportFocus()
 
-			case disableBracketedPasteMsg:
-				p.renderer.disableBracketedPaste()
+		case disableReportFocusMsg:
+			p.renderer.DisableReportFocus()
 
-			case enableReportFocusMsg:
-				p.renderer.enableReportFocus()
+		case execMsg:
+			// NB: this blocks.
+			p.exec(msg.cmd, msg.fn)
 
-			case disableReportFocusMsg:
-				p.renderer.disableReportFocus()
+		case BatchMsg:
+			for _, cmd := range msg {
+				if !dispatch(cmd) {
+					exit = true
+					return model, nil
+				}
+			}
+			return model, nil
 
-			case execMsg:
-				// NB: this blocks.
-				p.exec(msg.cmd, msg.fn)
+		case sequenceMsg:
+			if p.tracer != nil {
+				cmds := make(sequenceMsg, len(msg))
+				for i, cmd := range msg {
+					if cmd != nil {
+						cmds[i] = p.tracer.cmd(cmd)
+					}
+				}
+				msg = cmds
+			}
 
-			case BatchMsg:
+			if p.startupOptions.has(withSynchronousCommands) {
 				for _, cmd := range msg {
-					select {
-					case <-p.ctx.Done():
-						return model, nil
-					case cmds <- cmd:
+					if cmd == nil {
+						continue
 					}
+					result := p.runCmd(cmd)
+					if batchMsg, ok := result.(BatchMsg); ok {
+						for _, cmd := range batchMsg {
+							dispatch(cmd)
+						}
+						continue
+					}
+					enqueue(result)
 				}
-				continue
+				break
+			}
 
-			case sequenceMsg:
-				go func() {
-					// Execute commands one at a time, in order.
-					for _, cmd := range msg {
-						if cmd == nil {
-							continue
+			go func() {
+				defer p.catchCmdPanic()
+
+				// send sends the message of a command. Commands that take a
+				// context are run right away, so that the next command waits
+				// for them too.
+				send := func(msg Msg) {
+					switch msg := msg.(type) {
+					case contextCmdMsg:
+						<-p.goContextCmd(p.contextCmd(msg))
+						return
+
+					case keyedCmdMsg:
+						// Keyed commands are started by the event loop,
+						// which closes done once the command is done.
+						msg.done = make(chan struct{})
+						p.Send(msg)
+						select {
+						case <-p.ctx.Done():
+						case <-msg.done:
 						}
+						return
+					}
+					p.Send(msg)
+				}
+
+				// Execute commands one at a time, in order.
+				for _, cmd := range msg {
+					if cmd == nil {
+						continue
+					}
 
-						msg := cmd()
-						if batchMsg, ok := msg.(BatchMsg); ok {
-							g, _ := errgroup.WithContext(p.ctx)
-							for _, cmd := range batchMsg {
-								cmd := cmd
-								g.Go(func() error {
-									p.Send(cmd())
-									return nil
-								})
+					msg := p.runCmd(cmd)
+					if batchMsg, ok := msg.(BatchMsg); ok {
+						g, _ := errgroup.WithContext(p.ctx)
+						for _, cmd := range batchMsg {
+							if cmd == nil {
+								continue
 							}
+							if p.tracer != nil {
+								cmd = p.tracer.cmd(cmd)
+							}
+							g.Go(func() error {
+								defer p.catchCmdPanic()
 
-							//nolint:errcheck,gosec
-							g.Wait() // wait for all commands from batch msg to finish
-							continue
+								send(p.runCmd(cmd))
+								return nil
+							})
 						}
 
-						p.Send(msg)
+						//nolint:errcheck,gosec
+						g.Wait() // wait for all commands from batch msg to finish
+						continue
 					}
-				}()
 
-			case setWindowTitleMsg:
-				p.SetWindowTitle(string(msg))
+					send(msg)
+				}
+			}()
 
-			case windowSizeMsg:
-				go p.checkResize()
-			}
+		case setWindowTitleMsg:
+			p.SetWindowTitle(string(msg))
+
+		case setClipboardMsg:
+			p.execute(ansi.SetClipboard(msg.selection, msg.content))
+
+		case readClipboardMsg:
+			p.execute(ansi.RequestClipboard(byte(msg)))
+
+		case queryMsg:
+			p.execute(msg.seq)
+
+		case requestCursorPositionMsg:
+			p.cursorRequests++
+			p.execute(ansi.RequestCursorPositionReport)
+
+		case terminalQueryMsg:
+			p.startQuery(msg)
+
+		case windowSizeMsg:
+			go p.checkResize()
 
-			// Process internal messages for the renderer.
-			if r, ok := p.renderer.(*standardRenderer); ok {
-				r.handleMessages(msg)
+		case WindowSizeMsg:
+			if p.recorder != nil {
+				p.recorder.resize(msg.Width, msg.Height)
 			}
+		}
+
+		// Process internal messages for the renderer.
+		if r, ok := p.renderer.(msgHandler); ok {
+			r.HandleMessage(msg)
+		}
+
+		skipped = false
+		return model.Update(msg)
+	}
+
+	// Wrap the update with the middleware, the first one outermost.
+	update := handle
+	for i := len(p.middleware) - 1; i >= 0; i-- {
+		update = p.middleware[i](update)
+	}
+
+	// Report panics in update to the model, if requested.
+	if p.panicMsgs() {
+		update = recoverUpdate(update, enqueue)
+	}
 
-			var cmd Cmd
-			model, cmd = model.Update(msg) // run update
+	for {
+		var msg Msg
+		next = 0
+		if len(p.queue) > 0 {
+			select {
+			case <-p.ctx.Done():
+				return model, nil
+			case err := <-p.errs:
+				return model, err
+			default:
+			}
 
+			// Messages from commands run synchronously come first.
+			msg, p.queue = p.queue[0], p.queue[1:]
+		} else {
 			select {
 			case <-p.ctx.Done():
 				return model, nil
-			case cmds <- cmd: // process command (if any)
+
+			case err := <-p.errs:
+				return model, err
+
+			case msg = <-p.msgs:
+			}
+		}
+
+		// Drop the results of superseded keyed commands.
+		received := msg
+		if msg = p.resolveKeyedCmd(msg); msg == nil {
+			p.traceMsg(received, 0, 0)
+			continue
+		}
+
+		// Drop the messages of stopped subscriptions.
+		if msg = p.resolveSubscription(msg); msg == nil {
+			p.traceMsg(received, 0, 0)
+			continue
+		}
+
+		// Start the commands that take a context. They're started before
+		// the middleware, which could drop them and leave a sequence waiting
+		// for them.
+		switch m := msg.(type) {
+		case contextCmdMsg:
+			start(p.contextCmd(m))
+			p.traceMsg(received, 0, 0)
+			continue
+
+		case keyedCmdMsg:
+			cmd := p.keyedCmd(m)
+			switch {
+			case m.done == nil:
+				start(cmd)
+			case cmd == nil:
+				close(m.done)
+			default:
+				p.goContext(func() {
+					defer close(m.done)
+					p.Send(cmd())
+				})
 			}
+			p.traceMsg(received, 0, 0)
+			continue
+		}
 
-			p.renderer.write(model.View()) // send view to renderer
+		// Tell cursor position reports apart from key presses.
+		msg = p.resolveCursorPosition(msg)
+		received = msg
+
+		// Enable synchronized output if the terminal supports it.
+		if msg = p.detectSyncOutput(msg); msg == nil {
+			p.traceMsg(received, 0, 0)
+			continue
 		}
+
+		// Collect the replies to terminal queries.
+		if msg = p.collectQueryReply(msg); msg == nil {
+			p.traceMsg(received, 0, 0)
+			continue
+		}
+
+		var cmd Cmd
+		skipped = true
+		start := time.Now()
+		model, cmd = update(model, msg) // run update
+		updateTime := time.Since(start)
+		if exit {
+			p.traceMsg(msg, updateTime, 0)
+			return model, exitErr
+		}
+
+		if !dispatch(cmd) { // process command (if any)
+			return model, nil
+		}
+		if skipped {
+			p.traceMsg(msg, updateTime, 0)
+			continue
+		}
+		p.updateSubscriptions(model) // follow the subscriptions of the model
+
+		start = time.Now()
+		p.render(model) // send view to renderer
+		p.traceMsg(msg, updateTime, time.Since(start))
+	}
+}
+
+// execute writes an escape sequence to the terminal.
+func (p *Program) execute(seq string) {
+	if w, ok := p.renderer.(sequenceWriter); ok {
+		w.WriteSequence(seq)
+		return
 	}
+	_, _ = io.WriteString(p.output, seq)
 }
 
 // Run initializes the program and runs its event loops, blocking until it gets
@@ -603,9 +963,45 @@ func (p *Program) Run() (returnModel Model, returnErr error) {
 		}()
 	}
 
+	// Record the session if requested through the environment.
+	if path := p.getenv(recordEnv); path != "" && p.recorder == nil {
+		f, err := os.Create(path)
+		if err != nil {
+			return p.initialModel, fmt.Errorf("error creating recording: %w", err)
+		}
+		defer f.Close() //nolint:errcheck
+		p.recorder = newRecorder(f)
+	}
+
+	// Write a trace log if requested through the environment.
+	if path := p.getenv(traceEnv); path != "" && p.tracer == nil {
+		f, err := os.Create(path)
+		if err != nil {
+			return p.initialModel, fmt.Errorf("error creating trace log: %w", err)
+		}
+		defer f.Close() //nolint:errcheck
+		p.tracer = newTracer(f)
+	}
+
 	// If no renderer is set use the standard one.
 	if p.renderer == nil {
-		p.renderer = newRenderer(p.output, p.startupOptions.has(withANSICompressor), p.fps)
+		out := p.output
+		if p.recorder != nil {
+			out = &recordingWriter{w: out, rec: p.recorder}
+		}
+		if p.tracer != nil {
+			out = &tracingWriter{w: out, tr: p.tracer}
+		}
+		if p.startupOptions.has(withErrorMsgs) {
+			out = &errorWriter{w: out, p: p}
+		}
+		p.renderer = newRenderer(out, p.startupOptions.has(withANSICompressor), p.fps, p.startupOptions.has(withCellRenderer))
+	}
+
+	// Start recording before anything gets written to the terminal.
+	if p.recorder != nil {
+		width, height := p.outputSize()
+		p.recorder.begin(width, height, p.environ)
 	}
 
 	// Check if output is a TTY before entering raw mode, hiding the cursor and
@@ -616,20 +1012,20 @@ func (p *Program) Run() (returnModel Model, returnErr error) {
 
 	// Honor program startup options.
 	if p.startupTitle != "" {
-		p.renderer.setWindowTitle(p.startupTitle)
+		p.renderer.SetWindowTitle(p.startupTitle)
 	}
 	if p.startupOptions&withAltScreen != 0 {
-		p.renderer.enterAltScreen()
+		p.renderer.EnterAltScreen()
 	}
 	if p.startupOptions&withoutBracketedPaste == 0 {
-		p.renderer.enableBracketedPaste()
+		p.renderer.EnableBracketedPaste()
 	}
 	if p.startupOptions&withMouseCellMotion != 0 {
-		p.renderer.enableMouseCellMotion()
-		p.renderer.enableMouseSGRMode()
+		p.renderer.EnableMouseCellMotion()
+		p.renderer.EnableMouseSGRMode()
 	} else if p.startupOptions&withMouseAllMotion != 0 {
-		p.renderer.enableMouseAllMotion()
-		p.renderer.enableMouseSGRMode()
+		p.renderer.EnableMouseAllMotion()
+		p.renderer.EnableMouseSGRMode()
 	}
 
 	// XXX: Should we enable mouse mode on Windows?
@@ -637,15 +1033,25 @@ func (p *Program) Run() (returnModel Model, returnErr error) {
 	p.mouseMode = p.startupOptions&withMouseCellMotion != 0 || p.startupOptions&withMouseAllMotion != 0
 
 	if p.startupOptions&withReportFocus != 0 {
-		p.renderer.enableReportFocus()
+		p.renderer.EnableReportFocus()
 	}
+	p.enableKeyboardEnhancements()
+	p.initSyncOutput()
 
 	// Start the renderer.
-	p.renderer.start()
+	p.renderer.Start()
 
 	// Initialize the program.
 	model := p.initialModel
-	if initCmd := model.Init(); initCmd != nil {
+	start := time.Now()
+	initCmd := model.Init()
+	initTime := time.Since(start)
+	if initCmd != nil && p.tracer != nil {
+		initCmd = p.tracer.cmd(initCmd)
+	}
+	if initCmd != nil && p.startupOptions.has(withSynchronousCommands) {
+		p.queue = append(p.queue, p.runCmd(initCmd))
+	} else if initCmd != nil {
 		ch := make(chan struct{})
 		p.handlers.add(ch)
 
@@ -659,8 +1065,15 @@ func (p *Program) Run() (returnModel Model, returnErr error) {
 		}()
 	}
 
+	// Start the subscriptions of the model.
+	p.updateSubscriptions(model)
+
 	// Render the initial view.
-	p.renderer.write(model.View())
+	start = time.Now()
+	p.render(model)
+	if p.tracer != nil {
+		p.tracer.init(initTime, time.Since(start))
+	}
 
 	// Subscribe to user input.
 	if p.input != nil {
@@ -672,6 +1085,11 @@ func (p *Program) Run() (returnModel Model, returnErr error) {
 	// Handle resize events.
 	p.handlers.add(p.handleResize())
 
+	// Replay input events.
+	if len(p.replayEvents) > 0 {
+		p.handlers.add(p.replay())
+	}
+
 	// Process commands.
 	p.handlers.add(p.handleCommands(cmds))
 
@@ -699,7 +1117,7 @@ func (p *Program) Run() (returnModel Model, returnErr error) {
 	} else {
 		// Graceful shutdown of the program (not killed):
 		// Ensure we rendered the final state of the model.
-		p.renderer.write(model.View())
+		p.render(model)
 	}
 
 	// Restore terminal state.
@@ -741,6 +1159,18 @@ func (p *Program) Send(msg Msg) {
 	}
 }
 
+// Resize sets the size of the window, as if the terminal was resized: the
+// renderer lays out frames for the new size and the program receives a
+// [WindowSizeMsg]. Use it when the output isn't a terminal, such as when
+// serving a program over a socket or bridging it to a web page, to pass on
+// the size of the remote window.
+//
+// Like [Program.Send], this blocks if the program hasn't started yet and is
+// a no-op if it has terminated.
+func (p *Program) Resize(width, height int) {
+	p.Send(WindowSizeMsg{Width: width, Height: height})
+}
+
 // Quit is a convenience function for quitting Bubble Tea programs. Use it
 // when you need to shut down a Bubble Tea program from the outside.
 //
@@ -789,13 +1219,16 @@ func (p *Program) shutdown(kill bool) {
 
 	if p.renderer != nil {
 		if kill {
-			p.renderer.kill()
+			p.renderer.Kill()
 		} else {
-			p.renderer.stop()
+			p.renderer.Stop()
 		}
 	}
 
 	_ = p.restoreTerminalState()
+
+	// Let context commands unwind.
+	p.waitForContextCmds()
 }
 
 // recoverFromPanic recovers from a panic, prints the stack trace, and restores
@@ -833,10 +1266,10 @@ func (p *Program) ReleaseTerminal() error {
 	p.waitForReadLoop()
 
 	if p.renderer != nil {
-		p.renderer.stop()
-		p.altScreenWasActive = p.renderer.altScreen()
-		p.bpWasActive = p.renderer.bracketedPasteActive()
-		p.reportFocus = p.renderer.reportFocus()
+		p.renderer.Stop()
+		p.altScreenWasActive = p.renderer.AltScreen()
+		p.bpWasActive = p.renderer.BracketedPasteActive()
+		p.reportFocus = p.renderer.ReportFocus()
 	}
 
 	return p.restoreTerminalState()
@@ -855,20 +1288,21 @@ func (p *Program) RestoreTerminal() error {
 		return err
 	}
 	if p.altScreenWasActive {
-		p.renderer.enterAltScreen()
+		p.renderer.EnterAltScreen()
 	} else {
 		// entering alt screen already causes a repaint.
 		go p.Send(repaintMsg{})
 	}
 	if p.renderer != nil {
-		p.renderer.start()
+		p.renderer.Start()
 	}
 	if p.bpWasActive {
-		p.renderer.enableBracketedPaste()
+		p.renderer.EnableBracketedPaste()
 	}
 	if p.reportFocus {
-		p.renderer.enableReportFocus()
+		p.renderer.EnableReportFocus()
 	}
+	p.enableKeyboardEnhancements()
 
 	// If the output is a terminal, it may have been resized while another
 	// process was at the foreground, in which case we may not have received
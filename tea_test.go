@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -236,6 +238,50 @@ func testTeaWithFilter(t *testing.T, preventCount uint32) {
 	}
 }
 
+type viewCountModel struct {
+	views atomic.Int32
+}
+
+func (m *viewCountModel) Init() Cmd               { return nil }
+func (m *viewCountModel) Update(Msg) (Model, Cmd) { return m, nil }
+
+func (m *viewCountModel) View() string {
+	m.views.Add(1)
+	return "views\n"
+}
+
+func TestTeaWithFilterSkipsRender(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+
+	m := &viewCountModel{}
+	p := NewProgram(m,
+		WithInput(&in),
+		WithOutput(&buf),
+		WithFilter(func(_ Model, msg Msg) Msg {
+			if _, ok := msg.(QuitMsg); ok {
+				return msg
+			}
+			return nil
+		}))
+
+	go func() {
+		for i := 0; i < 10; i++ {
+			p.Send(incrementMsg{})
+		}
+		p.Quit()
+	}()
+
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The initial and the final render only.
+	if views := m.views.Load(); views != 2 {
+		t.Errorf("expected the view to be rendered 2 times, got %d", views)
+	}
+}
+
 func TestTeaKill(t *testing.T) {
 	var buf bytes.Buffer
 	var in bytes.Buffer
@@ -424,6 +470,80 @@ func TestTeaSequenceMsgWithBatchMsg(t *testing.T) {
 	}
 }
 
+func TestTeaSynchronousCommands(t *testing.T) {
+	inc := func() Msg {
+		return incrementMsg{}
+	}
+	batch := func() Msg {
+		return BatchMsg{inc, inc}
+	}
+
+	for _, msg := range []Msg{
+		BatchMsg{inc, batch, inc, Quit},
+		sequenceMsg{batch, inc, inc, Quit},
+	} {
+		var buf bytes.Buffer
+		var in bytes.Buffer
+
+		m := &testModel{}
+		p := NewProgram(m, WithInput(&in), WithOutput(&buf), WithSynchronousCommands())
+		go p.Send(msg)
+
+		if _, err := p.Run(); err != nil {
+			t.Fatal(err)
+		}
+
+		// Quit comes last, so every increment must have been processed.
+		if m.counter.Load() != 4 {
+			t.Fatalf("counter should be 4, got %d", m.counter.Load())
+		}
+	}
+}
+
+type flowModel struct {
+	msgs  []string
+	views int
+}
+
+func (m *flowModel) Init() Cmd {
+	inc := func() Msg {
+		return incrementMsg{}
+	}
+	return Batch(inc, Sequence(inc, Quit))
+}
+
+func (m *flowModel) Update(msg Msg) (Model, Cmd) {
+	m.msgs = append(m.msgs, fmt.Sprintf("%T", msg))
+	return m, nil
+}
+
+func (m *flowModel) View() string {
+	m.views++
+	return ""
+}
+
+func TestTeaBatchAndSequenceFlow(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+
+	m := &flowModel{}
+	p := NewProgram(m, WithInput(&in), WithOutput(&buf), WithSynchronousCommands())
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Batches only hand their commands over, while sequences reach the
+	// model.
+	want := []string{"tea.incrementMsg", "tea.sequenceMsg", "tea.incrementMsg"}
+	if !reflect.DeepEqual(m.msgs, want) {
+		t.Errorf("expected messages %q, got %q", want, m.msgs)
+	}
+	// The initial and final views, and one per update.
+	if m.views != len(want)+2 {
+		t.Errorf("expected %d views, got %d", len(want)+2, m.views)
+	}
+}
+
 func TestTeaSend(t *testing.T) {
 	var buf bytes.Buffer
 	var in bytes.Buffer
@@ -442,6 +562,65 @@ func TestTeaSend(t *testing.T) {
 	p.Send(Quit())
 }
 
+type windowSizeModel struct {
+	mtx   sync.Mutex
+	sizes []WindowSizeMsg
+	first chan struct{}
+}
+
+func (m *windowSizeModel) Init() Cmd {
+	return nil
+}
+
+func (m *windowSizeModel) Update(msg Msg) (Model, Cmd) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if msg, ok := msg.(WindowSizeMsg); ok {
+		m.sizes = append(m.sizes, msg)
+		switch len(m.sizes) {
+		case 1:
+			close(m.first)
+		case 2:
+			return m, Quit
+		}
+	}
+	return m, nil
+}
+
+func (m *windowSizeModel) View() string {
+	return "0123456789"
+}
+
+func TestTeaWindowSize(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+
+	m := &windowSizeModel{first: make(chan struct{})}
+	p := NewProgram(m, WithInput(&in), WithOutput(&buf), WithWindowSize(40, 10))
+	go func() {
+		// Resize once the initial size is in.
+		<-m.first
+		p.Resize(5, 3)
+	}()
+
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	want := []WindowSizeMsg{{Width: 40, Height: 10}, {Width: 5, Height: 3}}
+	if !reflect.DeepEqual(m.sizes, want) {
+		t.Fatalf("expected window sizes %v, got %v", want, m.sizes)
+	}
+
+	r := p.renderer.(*standardRenderer)
+	if last := m.sizes[1]; r.width != last.Width || r.height != last.Height {
+		t.Errorf("expected the renderer to be %dx%d, got %dx%d", last.Width, last.Height, r.width, r.height)
+	}
+}
+
 func TestTeaNoRun(t *testing.T) {
 	var buf bytes.Buffer
 	var in bytes.Buffer
@@ -0,0 +1,378 @@
+// Package teatest provides helpers to test Bubble Tea programs end to end.
+//
+// A [TestModel] runs a model in a [tea.Program] on a virtual terminal of a
+// given size, a [vt.Terminal] that interprets everything the program
+// writes. Tests can type keys, paste text, send messages and resize the
+// terminal, wait for the screen to show something, and finally check the
+// model returned by the program and the frames it rendered:
+//
+//	tm := teatest.NewTestModel(t, model, teatest.WithInitialTermSize(80, 24))
+//	tm.Type("hello")
+//	tm.WaitFor(func(screen string) bool {
+//		return strings.Contains(screen, "hello")
+//	})
+//	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+//	m := tm.FinalModel(teatest.WithFinalTimeout(time.Second))
+//
+// Pass [tea.WithSynchronousCommands] with [WithProgramOptions] to run
+// commands synchronously, so the program behaves the same way on every run.
+package teatest
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/bubbletea/vt"
+)
+
+// update makes golden file comparisons update the golden files instead.
+var update = flag.Bool("update", false, "update .golden files")
+
+// Defaults.
+const (
+	defaultWidth         = 80
+	defaultHeight        = 24
+	defaultWaitDuration  = time.Second
+	defaultCheckInterval = 50 * time.Millisecond
+	defaultFinalTimeout  = 5 * time.Second
+)
+
+// TestOption is an option for [NewTestModel].
+type TestOption func(*testOptions)
+
+type testOptions struct {
+	width, height int
+	programOpts   []tea.ProgramOption
+}
+
+// WithInitialTermSize sets the size of the virtual terminal, 80x24 by
+// default. The program receives a [tea.WindowSizeMsg] with this size when it
+// starts.
+func WithInitialTermSize(width, height int) TestOption {
+	return func(o *testOptions) {
+		o.width, o.height = width, height
+	}
+}
+
+// WithProgramOptions adds options to the program under test, e.g.
+// [tea.WithSynchronousCommands] for a deterministic program.
+func WithProgramOptions(opts ...tea.ProgramOption) TestOption {
+	return func(o *testOptions) {
+		o.programOpts = append(o.programOpts, opts...)
+	}
+}
+
+// TestModel is a model running in a program on a virtual terminal.
+type TestModel struct {
+	tb      testing.TB
+	program *tea.Program
+	term    *terminal
+	in      *io.PipeWriter
+
+	done  chan struct{}
+	model tea.Model
+	err   error
+}
+
+// NewTestModel starts a program running the given model on a virtual
+// terminal. The program is killed when the test finishes, if it's still
+// running by then.
+func NewTestModel(tb testing.TB, m tea.Model, options ...TestOption) *TestModel {
+	tb.Helper()
+
+	opts := testOptions{width: defaultWidth, height: defaultHeight}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	r, w := io.Pipe()
+	tm := &TestModel{
+		tb:   tb,
+		term: &terminal{Terminal: vt.New(opts.width, opts.height)},
+		in:   w,
+		done: make(chan struct{}),
+	}
+	tm.program = tea.NewProgram(m, append([]tea.ProgramOption{
+		tea.WithInput(r),
+		tea.WithOutput(tm.term),
+		tea.WithoutSignals(),
+		tea.WithoutSignalHandler(),
+		tea.WithWindowSize(opts.width, opts.height),
+	}, opts.programOpts...)...)
+
+	go func() {
+		tm.model, tm.err = tm.program.Run()
+		_ = w.Close()
+		close(tm.done)
+	}()
+
+	tb.Cleanup(func() {
+		tm.program.Kill()
+		<-tm.done
+	})
+
+	return tm
+}
+
+// Send sends a message to the program.
+func (tm *TestModel) Send(msg tea.Msg) {
+	tm.program.Send(msg)
+}
+
+// Type types the given text one key at a time, as if it was typed on a
+// keyboard.
+func (tm *TestModel) Type(s string) {
+	for _, r := range s {
+		tm.write(string(r))
+	}
+}
+
+// Input writes the given bytes to the program's input at once, e.g.
+// "\x1b[A" for the up arrow.
+func (tm *TestModel) Input(s string) {
+	tm.write(s)
+}
+
+// Paste pastes the given text using bracketed paste.
+func (tm *TestModel) Paste(s string) {
+	tm.write("\x1b[200~" + s + "\x1b[201~")
+}
+
+// Mouse sends a mouse event to the program.
+func (tm *TestModel) Mouse(ev tea.MouseEvent) {
+	tm.program.Send(tea.MouseMsg(ev))
+}
+
+// Resize resizes the virtual terminal and informs the program with a
+// [tea.WindowSizeMsg].
+func (tm *TestModel) Resize(width, height int) {
+	tm.term.Resize(width, height)
+	tm.program.Resize(width, height)
+}
+
+// Quit quits the program.
+func (tm *TestModel) Quit() {
+	tm.program.Quit()
+}
+
+// Screen returns the text currently shown on the virtual terminal, without
+// styles. See [vt.Terminal.String].
+func (tm *TestModel) Screen() string {
+	return tm.term.String()
+}
+
+// Terminal returns the virtual terminal the program runs on, e.g. to check
+// the style of a cell.
+func (tm *TestModel) Terminal() *vt.Terminal {
+	return tm.term.Terminal
+}
+
+// Frames returns the distinct screens shown on the virtual terminal so far,
+// in order. As the renderer draws at a fixed frame rate, views rendered in
+// quick succession may not all show up as frames.
+func (tm *TestModel) Frames() []string {
+	return tm.term.allFrames()
+}
+
+func (tm *TestModel) write(s string) {
+	select {
+	case <-tm.done:
+		tm.tb.Errorf("program finished, can't write %q to its input", s)
+		return
+	default:
+	}
+	if _, err := io.WriteString(tm.in, s); err != nil {
+		tm.tb.Errorf("could not write %q to the program's input: %v", s, err)
+	}
+}
+
+// WaitForOption is an option for [TestModel.WaitFor].
+type WaitForOption func(*waitForOptions)
+
+type waitForOptions struct {
+	duration      time.Duration
+	checkInterval time.Duration
+}
+
+// WithDuration sets how long to wait for the condition. The default is one
+// second.
+func WithDuration(d time.Duration) WaitForOption {
+	return func(o *waitForOptions) {
+		o.duration = d
+	}
+}
+
+// WithCheckInterval sets how often the condition is checked.
+func WithCheckInterval(d time.Duration) WaitForOption {
+	return func(o *waitForOptions) {
+		o.checkInterval = d
+	}
+}
+
+// WaitFor waits until the condition holds for the current screen, failing
+// the test if it doesn't within the wait duration.
+func (tm *TestModel) WaitFor(condition func(screen string) bool, options ...WaitForOption) {
+	tm.tb.Helper()
+
+	opts := waitForOptions{
+		duration:      defaultWaitDuration,
+		checkInterval: defaultCheckInterval,
+	}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	deadline := time.Now().Add(opts.duration)
+	for {
+		screen := tm.Screen()
+		if condition(screen) {
+			return
+		}
+		if time.Now().After(deadline) {
+			tm.tb.Fatalf("condition not met after %s, last screen:\n%s", opts.duration, screen)
+			return
+		}
+		time.Sleep(opts.checkInterval)
+	}
+}
+
+// FinalOption is an option for [TestModel.FinalModel] and
+// [TestModel.WaitFinished].
+type FinalOption func(*finalOptions)
+
+type finalOptions struct {
+	timeout time.Duration
+}
+
+// WithFinalTimeout sets how long to wait for the program to finish. The
+// default is five seconds.
+func WithFinalTimeout(d time.Duration) FinalOption {
+	return func(o *finalOptions) {
+		o.timeout = d
+	}
+}
+
+// WaitFinished waits for the program to finish, failing the test if it
+// doesn't within the timeout or if it returns an error.
+func (tm *TestModel) WaitFinished(options ...FinalOption) {
+	tm.tb.Helper()
+
+	opts := finalOptions{timeout: defaultFinalTimeout}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	select {
+	case <-tm.done:
+	case <-time.After(opts.timeout):
+		tm.tb.Fatalf("program did not finish after %s", opts.timeout)
+		return
+	}
+
+	if tm.err != nil {
+		tm.tb.Fatalf("program returned an error: %v", tm.err)
+	}
+}
+
+// FinalModel waits for the program to finish and returns the model it
+// returned.
+func (tm *TestModel) FinalModel(options ...FinalOption) tea.Model {
+	tm.tb.Helper()
+	tm.WaitFinished(options...)
+	return tm.model
+}
+
+// FinalScreen waits for the program to finish and returns the text shown on
+// the virtual terminal once it restored the terminal. For programs that use
+// the alternate screen, that's the main screen.
+func (tm *TestModel) FinalScreen(options ...FinalOption) string {
+	tm.tb.Helper()
+	tm.WaitFinished(options...)
+	return tm.Screen()
+}
+
+// RequireEqualOutput compares out with the golden file of the test, in the
+// testdata directory and named after the test. Run the tests with -update to
+// write the golden file instead.
+func RequireEqualOutput(tb testing.TB, out []byte) {
+	tb.Helper()
+
+	path := filepath.Join("testdata", filepath.FromSlash(tb.Name())+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil { //nolint:mnd
+			tb.Fatal(err)
+		}
+		if err := os.WriteFile(path, out, 0o600); err != nil { //nolint:mnd
+			tb.Fatal(err)
+		}
+		return
+	}
+
+	golden, err := os.ReadFile(path)
+	if err != nil {
+		tb.Fatalf("could not read golden file, run the tests with -update to create it: %v", err)
+	}
+	if !bytes.Equal(golden, out) {
+		tb.Fatalf("output does not match %s:\n%s", path, diff(string(golden), string(out)))
+	}
+}
+
+// RequireEqualFrames compares the frames shown so far, see [TestModel.Frames],
+// with the golden file of the test. See [RequireEqualOutput].
+func (tm *TestModel) RequireEqualFrames(tb testing.TB) {
+	tb.Helper()
+	RequireEqualOutput(tb, []byte(strings.Join(tm.Frames(), "\n---\n")))
+}
+
+// diff describes the first line where want and got differ.
+func diff(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g || i >= len(wantLines) || i >= len(gotLines) {
+			return fmt.Sprintf("line %d:\nwant: %q\ngot:  %q", i+1, w, g)
+		}
+	}
+	return ""
+}
+
+// terminal is a virtual terminal that keeps the distinct screens it shows.
+type terminal struct {
+	*vt.Terminal
+
+	mtx    sync.Mutex
+	frames []string
+}
+
+func (t *terminal) Write(p []byte) (int, error) {
+	n, err := t.Terminal.Write(p)
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	if screen := t.String(); len(t.frames) == 0 || t.frames[len(t.frames)-1] != screen {
+		t.frames = append(t.frames, screen)
+	}
+	return n, err
+}
+
+func (t *terminal) allFrames() []string {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return append([]string(nil), t.frames...)
+}
@@ -0,0 +1,159 @@
+package teatest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type counterModel struct {
+	count  int
+	pasted string
+	width  int
+	height int
+	clicks int
+}
+
+type incrementMsg struct{}
+
+func (m counterModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m counterModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tea.MouseMsg:
+		if msg.Action == tea.MouseActionPress {
+			m.clicks++
+		}
+	case incrementMsg:
+		m.count++
+	case tea.KeyMsg:
+		switch {
+		case msg.Paste:
+			m.pasted = string(msg.Runes)
+		case msg.String() == "+":
+			return m, func() tea.Msg { return incrementMsg{} }
+		case msg.String() == "q":
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m counterModel) View() string {
+	return fmt.Sprintf("count: %d\npasted: %s\nsize: %dx%d\nclicks: %d\n", m.count, m.pasted, m.width, m.height, m.clicks)
+}
+
+func TestTestModel(t *testing.T) {
+	tm := NewTestModel(t, counterModel{}, WithInitialTermSize(20, 10))
+
+	tm.Type("++")
+	tm.WaitFor(func(screen string) bool {
+		return strings.Contains(screen, "count: 2")
+	})
+
+	tm.Paste("hello\nworld")
+	tm.WaitFor(func(screen string) bool {
+		return strings.Contains(screen, "pasted: hello")
+	})
+
+	tm.Mouse(tea.MouseEvent{X: 1, Y: 1, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+	tm.Resize(40, 2)
+	tm.WaitFor(func(screen string) bool {
+		// Only the bottom two lines fit the terminal.
+		return screen == "clicks: 1\n"
+	})
+
+	tm.Type("q")
+	m := tm.FinalModel(WithFinalTimeout(time.Second)).(counterModel)
+	if m.count != 2 {
+		t.Errorf("expected count to be 2, got %d", m.count)
+	}
+	if m.pasted != "hello\nworld" {
+		t.Errorf("expected pasted text %q, got %q", "hello\nworld", m.pasted)
+	}
+	if m.width != 40 || m.height != 2 {
+		t.Errorf("expected size 40x2, got %dx%d", m.width, m.height)
+	}
+}
+
+func TestTestModelStyles(t *testing.T) {
+	tm := NewTestModel(t, styledModel{}, WithInitialTermSize(20, 2))
+	tm.WaitFor(func(screen string) bool {
+		return strings.HasPrefix(screen, "bold plain")
+	})
+	if c := tm.Terminal().Cell(0, 0); !c.Style.Bold {
+		t.Errorf("expected the first cell to be bold, got %#v", c)
+	}
+	if c := tm.Terminal().Cell(5, 0); !c.Style.IsZero() {
+		t.Errorf("expected the sixth cell to be plain, got %#v", c)
+	}
+	tm.Quit()
+	tm.WaitFinished()
+}
+
+type styledModel struct{}
+
+func (m styledModel) Init() tea.Cmd                       { return nil }
+func (m styledModel) Update(tea.Msg) (tea.Model, tea.Cmd) { return m, nil }
+func (m styledModel) View() string                        { return "\x1b[1mbold\x1b[m plain" }
+
+func TestTestModelScreenWidth(t *testing.T) {
+	tm := NewTestModel(t, counterModel{}, WithInitialTermSize(5, 10))
+	tm.WaitFor(func(screen string) bool {
+		return strings.HasPrefix(screen, "count\n")
+	})
+	tm.Quit()
+	tm.WaitFinished()
+}
+
+func TestTestModelGolden(t *testing.T) {
+	tm := NewTestModel(t, counterModel{},
+		WithInitialTermSize(20, 10),
+		WithProgramOptions(tea.WithSynchronousCommands()),
+	)
+
+	tm.Type("+")
+	tm.WaitFor(func(screen string) bool {
+		return strings.Contains(screen, "count: 1")
+	})
+	tm.Type("+q")
+
+	RequireEqualOutput(t, []byte(tm.FinalScreen()))
+}
+
+type stepModel int
+
+func (m stepModel) Init() tea.Cmd { return nil }
+
+func (m stepModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == "+" {
+		m++
+	}
+	return m, nil
+}
+
+func (m stepModel) View() string { return fmt.Sprintf("step %d", int(m)) }
+
+func TestTestModelGoldenFrames(t *testing.T) {
+	tm := NewTestModel(t, stepModel(0), WithInitialTermSize(20, 2))
+	for _, screen := range []string{"step 0", "step 1", "step 2"} {
+		tm.WaitFor(func(s string) bool {
+			return strings.HasPrefix(s, screen)
+		})
+		tm.Type("+")
+	}
+	tm.WaitFor(func(s string) bool {
+		return strings.HasPrefix(s, "step 3")
+	})
+	tm.Quit()
+	tm.WaitFinished()
+
+	tm.RequireEqualFrames(t)
+}
@@ -0,0 +1,124 @@
+package tea
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/xo/terminfo"
+)
+
+// terminfoKeys maps the terminfo capabilities of keys to the keys.
+var terminfoKeys = func() map[string]Key {
+	m := map[string]Key{}
+	add := func(capName, key string) {
+		k, err := ParseKey(key)
+		if err != nil {
+			panic(err)
+		}
+		m[capName] = k
+	}
+
+	for capName, key := range map[string]string{
+		"kcuu1": "up",
+		"kcud1": "down",
+		"kcuf1": "right",
+		"kcub1": "left",
+		"khome": "home",
+		"kend":  "end",
+		"kich1": "insert",
+		"kdch1": "delete",
+		"kpp":   "pgup",
+		"knp":   "pgdown",
+		"kcbt":  "shift+tab",
+	} {
+		add(capName, key)
+	}
+
+	// The shifted keys. The extended capabilities of xterm add the keys
+	// with other modifiers, suffixed with the xterm modifier parameter:
+	// kUP3 is alt+up, kUP5 ctrl+up and so on.
+	for capName, key := range map[string]string{
+		"kUP":  "up",
+		"kDN":  "down",
+		"kRIT": "right",
+		"kLFT": "left",
+		"kHOM": "home",
+		"kEND": "end",
+		"kIC":  "insert",
+		"kDC":  "delete",
+		"kPRV": "pgup",
+		"kNXT": "pgdown",
+	} {
+		add(capName, "shift+"+key)
+		for n := 2; n <= 8; n++ {
+			add(capName+strconv.Itoa(n), KeyMod(n-1).String()+key)
+		}
+	}
+
+	// Function keys. Past f12, xterm numbers them as f1 to f12 with shift,
+	// ctrl, ctrl+shift, alt and alt+shift, but f13 to f20 are kept as is,
+	// as they are in legacy sequences.
+	fnMods := []KeyMod{0, ModShift, ModCtrl, ModCtrl | ModShift, ModAlt, ModAlt | ModShift}
+	for n := 1; n <= 63; n++ {
+		key := fmt.Sprintf("f%d", n)
+		if n > 20 { //nolint:mnd
+			key = fnMods[(n-1)/12].String() + fmt.Sprintf("f%d", (n-1)%12+1)
+		}
+		add(fmt.Sprintf("kf%d", n), key)
+	}
+	return m
+}()
+
+// loadTerminfoKeys returns the key sequences of the terminal named by $TERM,
+// read from its terminfo entry, or nil if it has none.
+func loadTerminfoKeys(getenv func(string) string) map[string]Key {
+	name := getenv("TERM")
+	if name == "" {
+		return nil
+	}
+
+	var ti *terminfo.Terminfo
+	for _, dir := range terminfoDirs(getenv) {
+		var err error
+		if ti, err = terminfo.Open(dir, name); err == nil {
+			break
+		}
+	}
+	if ti == nil {
+		return nil
+	}
+
+	seqs := map[string]Key{}
+	for _, caps := range []map[string][]byte{ti.StringCapsShort(), ti.ExtStringCapsShort()} {
+		for capName, seq := range caps {
+			k, ok := terminfoKeys[capName]
+			// Control characters, such as the backspace of some terminals,
+			// are keys of their own.
+			if !ok || len(seq) < 2 || seq[0] != '\x1b' { //nolint:mnd
+				continue
+			}
+			seqs[string(seq)] = k
+		}
+	}
+	return seqs
+}
+
+// terminfoDirs returns the directories terminfo entries are looked up in,
+// in order; see terminfo(5).
+func terminfoDirs(getenv func(string) string) []string {
+	var dirs []string
+	if dir := getenv("TERMINFO"); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	if home := getenv("HOME"); home != "" {
+		dirs = append(dirs, filepath.Join(home, ".terminfo"))
+	}
+	for _, dir := range strings.Split(getenv("TERMINFO_DIRS"), ":") {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return append(dirs, "/etc/terminfo", "/lib/terminfo", "/usr/share/terminfo")
+}
@@ -0,0 +1,102 @@
+package tea
+
+import (
+	"bytes"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTerminfoKeys(t *testing.T) {
+	for capName, want := range map[string]Key{
+		"kcuu1": {Type: KeyUp},
+		"kUP":   {Type: KeyShiftUp},
+		"kUP5":  {Type: KeyCtrlUp},
+		"kUP3":  {Type: KeyUp, Alt: true},
+		"kPRV5": {Type: KeyCtrlPgUp},
+		"kDC":   {Type: KeyDelete, Mod: ModShift},
+		"kf12":  {Type: KeyF12},
+		"kf20":  {Type: KeyF20},
+		"kf21":  {Type: KeyF9, Mod: ModShift},
+		"kf25":  {Type: KeyF1, Mod: ModCtrl},
+		"kf63":  {Type: KeyF3, Alt: true, Mod: ModShift},
+	} {
+		if got := terminfoKeys[capName]; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %v, got %v", capName, want, got)
+		}
+	}
+}
+
+func TestLoadTerminfoKeys(t *testing.T) {
+	getenv := func(env ...string) func(string) string {
+		p := NewProgram(nil, WithEnvironment(append(env, "HOME="+t.TempDir())))
+		return p.getenv
+	}
+
+	want := map[string]string{
+		"\x1b[xA":    "up",
+		"\x1b[x1;5A": "ctrl+up",
+		"\x1b[x3~":   "delete",
+		"\x1b[x3;2~": "shift+delete",
+		"\x1b[x2;3~": "alt+insert",
+		"\x1b[xH":    "home",
+		"\x1b[xP":    "f1",
+		"\x1b[x13~":  "f13",
+		"\x1b[x25~":  "ctrl+f1",
+		"\x1b[x63~":  "alt+shift+f3",
+	}
+	for _, env := range [][]string{
+		{"TERM=tea-test", "TERMINFO=testdata/terminfo"},
+		{"TERM=tea-test", "TERMINFO_DIRS=/nonexistent::testdata/terminfo"},
+	} {
+		got := map[string]string{}
+		for seq, k := range loadTerminfoKeys(getenv(env...)) {
+			got[seq] = k.String()
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: expected keys %q, got %q", env, want, got)
+		}
+	}
+
+	for _, env := range [][]string{
+		{"TERMINFO=testdata/terminfo"},
+		{"TERM=nonexistent", "TERMINFO=testdata/terminfo"},
+	} {
+		if got := loadTerminfoKeys(getenv(env...)); got != nil {
+			t.Errorf("%q: expected no keys, got %v", env, got)
+		}
+	}
+}
+
+func TestWithTerminfoKeys(t *testing.T) {
+	run := func(opts ...ProgramOption) []string {
+		t.Helper()
+		m := &keysModel{}
+		opts = append(opts,
+			WithEnvironment([]string{"TERM=tea-test", "TERMINFO=testdata/terminfo", "HOME=" + t.TempDir()}),
+			WithInput(strings.NewReader("\x1b[xA\x1b\x1b[xH\x1b[x25~\x1b[Aq")),
+			WithOutput(&bytes.Buffer{}))
+		if _, err := NewProgram(m, opts...).Run(); err != nil {
+			t.Fatal(err)
+		}
+		return m.keys
+	}
+
+	want := []string{"up", "alt+home", "ctrl+f1", "up"}
+	if got := run(WithTerminfoKeys()); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected keys %q, got %q", want, got)
+	}
+
+	// Key sequences given to the program take precedence.
+	want = []string{"up", "alt+home", "f5", "up"}
+	got := run(WithTerminfoKeys(), WithKeySequences(map[string]Key{"\x1b[x25~": {Type: KeyF5}}))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected keys %q, got %q", want, got)
+	}
+
+	// The terminfo entry is only read if asked.
+	if got := run(); slices.Contains(got, "alt+home") || slices.Contains(got, "ctrl+f1") {
+		t.Errorf("expected the terminfo keys not to be recognized, got %q", got)
+	}
+}
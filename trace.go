@@ -0,0 +1,184 @@
+package tea
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// traceEnv is the environment variable that makes a program write a trace
+// log to the given file, as if set with [WithTrace].
+const traceEnv = "TEA_TRACE"
+
+// maxTraceSummary is the maximum length of the message summaries in trace
+// logs.
+const maxTraceSummary = 256
+
+// Event types of trace logs.
+const (
+	traceInit   = "init"
+	traceMsg    = "msg"
+	traceCmd    = "cmd"
+	traceOutput = "output"
+)
+
+// traceEvent is a line of a trace log.
+type traceEvent struct {
+	Time  time.Time `json:"time"`
+	Event string    `json:"event"`
+
+	// Cmd is the ID of the command of cmd events.
+	Cmd uint64 `json:"cmd,omitempty"`
+
+	// Type and Msg are the type and summary of the message handled, or
+	// returned by the command.
+	Type string `json:"type,omitempty"`
+	Msg  string `json:"msg,omitempty"`
+
+	// Update and View are how long updating the model and rendering its
+	// view took.
+	Update time.Duration `json:"update,omitempty"`
+	View   time.Duration `json:"view,omitempty"`
+
+	// Cmds are the IDs of the commands spawned.
+	Cmds []uint64 `json:"cmds,omitempty"`
+
+	// Duration is how long the command ran, or the output took to write.
+	Duration time.Duration `json:"duration,omitempty"`
+
+	// Bytes is the number of bytes of output written.
+	Bytes int `json:"bytes,omitempty"`
+}
+
+// tracer writes a trace log of the messages and commands of a program as
+// JSON lines.
+type tracer struct {
+	mtx sync.Mutex
+	w   io.Writer
+
+	// lastCmd is the ID of the last command traced.
+	lastCmd uint64
+
+	// spawned are the IDs of the commands spawned since the last message
+	// was traced.
+	spawned []uint64
+
+	// err is the first write error, after which tracing stops.
+	err error
+}
+
+func newTracer(w io.Writer) *tracer {
+	return &tracer{w: w}
+}
+
+// cmd returns a command that runs cmd and traces it. The command is listed
+// with the next message or init event traced.
+func (t *tracer) cmd(cmd Cmd) Cmd {
+	t.mtx.Lock()
+	t.lastCmd++
+	id := t.lastCmd
+	t.spawned = append(t.spawned, id)
+	t.mtx.Unlock()
+
+	return func() Msg {
+		start := time.Now()
+		msg := cmd()
+		t.event(traceEvent{
+			Event:    traceCmd,
+			Cmd:      id,
+			Duration: time.Since(start),
+			Type:     traceType(msg),
+			Msg:      traceSummary(msg),
+		})
+		return msg
+	}
+}
+
+// init traces the initialization of the model.
+func (t *tracer) init(update, view time.Duration) {
+	t.event(traceEvent{Event: traceInit, Update: update, View: view})
+}
+
+// msg traces the handling of a message.
+func (t *tracer) msg(msg Msg, update, view time.Duration) {
+	t.event(traceEvent{
+		Event:  traceMsg,
+		Type:   traceType(msg),
+		Msg:    traceSummary(msg),
+		Update: update,
+		View:   view,
+	})
+}
+
+// traceMsg traces the handling of a message, if tracing.
+func (p *Program) traceMsg(msg Msg, update, view time.Duration) {
+	if p.tracer != nil {
+		p.tracer.msg(msg, update, view)
+	}
+}
+
+// output traces output written to the terminal.
+func (t *tracer) output(n int, duration time.Duration) {
+	t.event(traceEvent{Event: traceOutput, Bytes: n, Duration: duration})
+}
+
+func (t *tracer) event(e traceEvent) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if t.err != nil {
+		return
+	}
+	e.Time = time.Now()
+	if e.Event != traceCmd && e.Event != traceOutput {
+		e.Cmds, t.spawned = t.spawned, nil
+	}
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		t.err = err
+		return
+	}
+	_, t.err = t.w.Write(append(b, '\n'))
+}
+
+func traceType(msg Msg) string {
+	if msg == nil {
+		return ""
+	}
+	return fmt.Sprintf("%T", msg)
+}
+
+// traceSummary returns the Go syntax representation of the message,
+// shortened to maxTraceSummary bytes.
+func traceSummary(msg Msg) string {
+	if msg == nil {
+		return ""
+	}
+	s := fmt.Sprintf("%#v", msg)
+	if len(s) <= maxTraceSummary {
+		return s
+	}
+	// Don't cut a character in half.
+	n := maxTraceSummary
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "…"
+}
+
+// tracingWriter traces everything written to the terminal.
+type tracingWriter struct {
+	w  io.Writer
+	tr *tracer
+}
+
+func (w *tracingWriter) Write(p []byte) (int, error) {
+	start := time.Now()
+	n, err := w.w.Write(p)
+	w.tr.output(n, time.Since(start))
+	return n, err //nolint:wrapcheck
+}
@@ -0,0 +1,143 @@
+package tea
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// parseTrace parses a trace log into its events.
+func parseTrace(t *testing.T, data string) []traceEvent {
+	t.Helper()
+
+	var events []traceEvent
+	for _, line := range strings.Split(strings.TrimSuffix(data, "\n"), "\n") {
+		var ev traceEvent
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			t.Fatalf("invalid event %q: %v", line, err)
+		}
+		events = append(events, ev)
+	}
+	return events
+}
+
+func TestProgramTrace(t *testing.T) {
+	run := func(t *testing.T, opts ...ProgramOption) {
+		t.Helper()
+
+		var buf bytes.Buffer
+		in := bytes.NewBufferString("q")
+
+		p := NewProgram(&testModel{}, append(opts, WithInput(in), WithOutput(&buf))...)
+		if _, err := p.Run(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	check := func(t *testing.T, trace string) {
+		t.Helper()
+
+		// The key press spawns a command that quits.
+		var key, quit traceEvent
+		var cmd uint64
+		var output bool
+		for _, ev := range parseTrace(t, trace) {
+			switch ev.Event {
+			case traceMsg:
+				switch ev.Type {
+				case "tea.KeyMsg":
+					key = ev
+				case "tea.QuitMsg":
+					quit = ev
+				}
+			case traceCmd:
+				if ev.Type == "tea.QuitMsg" {
+					cmd = ev.Cmd
+				}
+			case traceOutput:
+				output = output || ev.Bytes > 0
+			}
+		}
+
+		if !strings.Contains(key.Msg, "113") {
+			t.Errorf("expected the key press in the summary, got %q", key.Msg)
+		}
+		if cmd == 0 || len(key.Cmds) != 1 || key.Cmds[0] != cmd {
+			t.Errorf("expected the key press to spawn command %d, got %v", cmd, key.Cmds)
+		}
+		if quit.Time.IsZero() {
+			t.Error("expected the quit message to be traced")
+		}
+		if !output {
+			t.Error("expected output to be traced")
+		}
+	}
+
+	t.Run("option", func(t *testing.T) {
+		var trace bytes.Buffer
+		run(t, WithTrace(&trace))
+		check(t, trace.String())
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "trace.jsonl")
+		run(t, WithEnvironment([]string{traceEnv + "=" + path}))
+
+		trace, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, string(trace))
+	})
+}
+
+func TestTraceSequence(t *testing.T) {
+	cmd := func(msg Msg) Cmd {
+		return func() Msg { return msg }
+	}
+
+	for _, synchronous := range []bool{false, true} {
+		var buf, in, trace bytes.Buffer
+		m := &contextCmdModel{init: Sequence(cmd("a"), Batch(cmd("b"), cmd("c")), cmd("quit"))}
+		opts := []ProgramOption{WithInput(&in), WithOutput(&buf), WithTrace(&trace)}
+		if synchronous {
+			opts = append(opts, WithSynchronousCommands())
+		}
+		if _, err := NewProgram(m, opts...).Run(); err != nil {
+			t.Fatal(err)
+		}
+
+		// Each command of the sequence is traced, including the batched ones.
+		var got []string
+		for _, ev := range parseTrace(t, trace.String()) {
+			if ev.Event == traceCmd && ev.Type == "string" {
+				got = append(got, ev.Msg)
+			}
+		}
+		slices.Sort(got)
+		if want := []string{`"a"`, `"b"`, `"c"`, `"quit"`}; !reflect.DeepEqual(got, want) {
+			t.Errorf("synchronous %v: expected commands returning %q, got %q", synchronous, want, got)
+		}
+	}
+}
+
+func TestTraceSummary(t *testing.T) {
+	if s := traceSummary(KeyMsg{Type: KeyEnter}); !strings.HasPrefix(s, "tea.KeyMsg{Type:13,") {
+		t.Errorf("unexpected summary %q", s)
+	}
+
+	s := traceSummary(strings.Repeat("世", maxTraceSummary))
+	if !utf8.ValidString(s) || !strings.HasSuffix(s, "…") || len(s) > maxTraceSummary+len("…") {
+		t.Errorf("expected a valid shortened summary, got %q", s)
+	}
+
+	if s := traceSummary(nil); s != "" {
+		t.Errorf("expected no summary of nil, got %q", s)
+	}
+}
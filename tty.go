@@ -32,7 +32,7 @@ func (p *Program) initTerminal() error {
 		return err
 	}
 
-	p.renderer.hideCursor()
+	p.renderer.HideCursor()
 	return nil
 }
 
@@ -40,16 +40,18 @@ func (p *Program) initTerminal() error {
 // Bubble Tea program.
 func (p *Program) restoreTerminalState() error {
 	if p.renderer != nil {
-		p.renderer.disableBracketedPaste()
-		p.renderer.showCursor()
+		p.renderer.DisableBracketedPaste()
+		p.renderer.ShowCursor()
 		p.disableMouse()
 
-		if p.renderer.reportFocus() {
-			p.renderer.disableReportFocus()
+		if p.renderer.ReportFocus() {
+			p.renderer.DisableReportFocus()
 		}
 
-		if p.renderer.altScreen() {
-			p.renderer.exitAltScreen()
+		p.disableKeyboardEnhancements()
+
+		if p.renderer.AltScreen() {
+			p.renderer.ExitAltScreen()
 
 			// give the terminal a moment to catch up
 			time.Sleep(time.Millisecond * 10) //nolint:mnd
@@ -96,12 +98,14 @@ func (p *Program) initCancelReader(cancel bool) error {
 func (p *Program) readLoop() {
 	defer close(p.readLoopDone)
 
-	err := readInputs(p.ctx, p.msgs, p.cancelReader)
+	var input io.Reader = p.cancelReader
+	if p.recorder != nil {
+		input = &recordingReader{CancelReader: p.cancelReader, rec: p.recorder}
+	}
+
+	err := readInputs(p.ctx, p.msgs, input, p.parser, p.escTimeout)
 	if !errors.Is(err, io.EOF) && !errors.Is(err, cancelreader.ErrCanceled) {
-		select {
-		case <-p.ctx.Done():
-		case p.errs <- err:
-		}
+		p.reportError(ErrorSourceInput, err)
 	}
 }
 
@@ -116,6 +120,20 @@ func (p *Program) waitForReadLoop() {
 	}
 }
 
+// outputSize returns the size of the output if it's a terminal, or zero.
+// Unlike checkResize, it can be used before the terminal is initialized.
+func (p *Program) outputSize() (width, height int) {
+	f, ok := p.output.(term.File)
+	if !ok || !term.IsTerminal(f.Fd()) {
+		return 0, 0
+	}
+	width, height, err := term.GetSize(f.Fd())
+	if err != nil {
+		return 0, 0
+	}
+	return width, height
+}
+
 // checkResize detects the current size of the output and informs the program
 // via a WindowSizeMsg.
 func (p *Program) checkResize() {
@@ -126,11 +144,7 @@ func (p *Program) checkResize() {
 
 	w, h, err := term.GetSize(p.ttyOutput.Fd())
 	if err != nil {
-		select {
-		case <-p.ctx.Done():
-		case p.errs <- err:
-		}
-
+		p.reportError(ErrorSourceResize, err)
 		return
 	}
 
@@ -0,0 +1,234 @@
+package vt
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Cell is a single cell of the terminal screen.
+type Cell struct {
+	// Content is the grapheme cluster drawn in the cell. It's empty for blank
+	// cells and for the cells covered by the right-hand side of a wide
+	// character.
+	Content string
+
+	// Width is the number of columns the content occupies. It's zero for the
+	// cells covered by a wide character to their left.
+	Width int
+
+	// Style is the style the cell was drawn with.
+	Style Style
+
+	// Link is the URL of the OSC 8 hyperlink the cell was drawn with, if any.
+	Link string
+}
+
+// blankCell returns an erased cell with the given style.
+func blankCell(style Style) Cell {
+	return Cell{Content: " ", Width: 1, Style: style}
+}
+
+// IsContinuation reports whether the cell is covered by a wide character to
+// its left.
+func (c Cell) IsContinuation() bool {
+	return c.Width == 0
+}
+
+// Style is the set of graphic rendition attributes of a cell. Nil colors are
+// the terminal's default colors.
+type Style struct {
+	Fg             ansi.Color
+	Bg             ansi.Color
+	UnderlineColor ansi.Color
+	Underline      ansi.UnderlineStyle
+
+	Bold          bool
+	Faint         bool
+	Italic        bool
+	Blink         bool
+	Reverse       bool
+	Conceal       bool
+	Strikethrough bool
+	Overline      bool
+}
+
+// IsZero reports whether the style is the default style.
+func (s Style) IsZero() bool {
+	return s == Style{}
+}
+
+// Apply updates the style with the parameters of an SGR sequence, as in
+// ESC [ params m, split on semicolons. No parameters reset the style.
+func (s *Style) Apply(params []string) {
+	if len(params) == 0 {
+		*s = Style{}
+		return
+	}
+	for i := 0; i < len(params); i++ {
+		p := params[i]
+		code, sub, hasSub := strings.Cut(p, ":")
+		n := 0
+		if code != "" {
+			var err error
+			if n, err = strconv.Atoi(code); err != nil {
+				continue
+			}
+		}
+
+		switch {
+		case n == 0:
+			*s = Style{}
+		case n == 1:
+			s.Bold = true
+		case n == 2: //nolint:mnd
+			s.Faint = true
+		case n == 3: //nolint:mnd
+			s.Italic = true
+		case n == 4: //nolint:mnd
+			s.Underline = ansi.SingleUnderlineStyle
+			if hasSub {
+				if u, err := strconv.Atoi(sub); err == nil {
+					s.Underline = ansi.UnderlineStyle(u) //nolint:gosec
+				}
+			}
+		case n == 5 || n == 6:
+			s.Blink = true
+		case n == 7: //nolint:mnd
+			s.Reverse = true
+		case n == 8: //nolint:mnd
+			s.Conceal = true
+		case n == 9: //nolint:mnd
+			s.Strikethrough = true
+		case n == 21: //nolint:mnd
+			s.Underline = ansi.DoubleUnderlineStyle
+		case n == 22: //nolint:mnd
+			s.Bold, s.Faint = false, false
+		case n == 23: //nolint:mnd
+			s.Italic = false
+		case n == 24: //nolint:mnd
+			s.Underline = ansi.NoUnderlineStyle
+		case n == 25: //nolint:mnd
+			s.Blink = false
+		case n == 27: //nolint:mnd
+			s.Reverse = false
+		case n == 28: //nolint:mnd
+			s.Conceal = false
+		case n == 29: //nolint:mnd
+			s.Strikethrough = false
+		case n >= 30 && n <= 37:
+			s.Fg = ansi.BasicColor(n - 30) //nolint:gosec
+		case n == 39: //nolint:mnd
+			s.Fg = nil
+		case n >= 40 && n <= 47:
+			s.Bg = ansi.BasicColor(n - 40) //nolint:gosec
+		case n == 49: //nolint:mnd
+			s.Bg = nil
+		case n == 53: //nolint:mnd
+			s.Overline = true
+		case n == 55: //nolint:mnd
+			s.Overline = false
+		case n == 59: //nolint:mnd
+			s.UnderlineColor = nil
+		case n >= 90 && n <= 97:
+			s.Fg = ansi.BasicColor(n - 90 + 8) //nolint:gosec
+		case n >= 100 && n <= 107:
+			s.Bg = ansi.BasicColor(n - 100 + 8) //nolint:gosec
+		case n == 38 || n == 48 || n == 58:
+			var c ansi.Color
+			if hasSub {
+				c = readColor(strings.Split(sub, ":"))
+			} else {
+				var used int
+				c, used = readColorParams(params[i+1:])
+				i += used
+			}
+			switch n {
+			case 38: //nolint:mnd
+				s.Fg = c
+			case 48: //nolint:mnd
+				s.Bg = c
+			default:
+				s.UnderlineColor = c
+			}
+		}
+	}
+}
+
+// readColorParams reads an extended color given as separate SGR parameters,
+// as in 38;5;n or 38;2;r;g;b. It returns the color and the number of
+// parameters it used.
+func readColorParams(params []string) (ansi.Color, int) {
+	if len(params) == 0 {
+		return nil, 0
+	}
+	switch params[0] {
+	case "5":
+		if len(params) < 2 { //nolint:mnd
+			return nil, len(params)
+		}
+		return readColor(params[:2]), 2 //nolint:mnd
+	case "2":
+		if len(params) < 4 { //nolint:mnd
+			return nil, len(params)
+		}
+		return readColor(params[:4]), 4 //nolint:mnd
+	}
+	return nil, 1
+}
+
+// readColor reads an extended color from its type and components, as in
+// 5:n or 2::r:g:b.
+func readColor(parts []string) ansi.Color {
+	if len(parts) == 0 {
+		return nil
+	}
+	atoi := func(s string) uint32 {
+		n, _ := strconv.Atoi(s)
+		return uint32(n & 0xff) //nolint:gosec,mnd
+	}
+	switch parts[0] {
+	case "5":
+		if len(parts) == 2 { //nolint:mnd
+			return ansi.ExtendedColor(atoi(parts[1])) //nolint:gosec
+		}
+	case "2":
+		// The color space identifier is optional.
+		if len(parts) == 5 { //nolint:mnd
+			parts = parts[1:]
+		}
+		if len(parts) == 4 { //nolint:mnd
+			return ansi.TrueColor(atoi(parts[1])<<16 | atoi(parts[2])<<8 | atoi(parts[3])) //nolint:mnd
+		}
+	}
+	return nil
+}
+
+// Line is a line of cells.
+type Line []Cell
+
+// String returns the text of the line, without styles and trailing spaces.
+func (l Line) String() string {
+	var b strings.Builder
+	for _, c := range l {
+		if c.IsContinuation() {
+			continue
+		}
+		if c.Content == "" {
+			b.WriteByte(' ')
+			continue
+		}
+		b.WriteString(c.Content)
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+// newLine returns a blank line of the given width.
+func newLine(width int, style Style) Line {
+	l := make(Line, width)
+	for i := range l {
+		l[i] = blankCell(style)
+	}
+	return l
+}
@@ -0,0 +1,211 @@
+package vt
+
+import (
+	"strconv"
+	"strings"
+)
+
+// csi is a parsed control sequence.
+type csi struct {
+	// prefix is the private parameter prefix, such as '?', or zero.
+	prefix byte
+	// intermediate is the intermediate byte, such as ' ', or zero.
+	intermediate byte
+	final        byte
+	params       []string
+}
+
+// parseCSI parses a control sequence starting with ESC [.
+func parseCSI(seq string) csi {
+	var c csi
+	seq = strings.TrimPrefix(seq, "\x1b[")
+	if seq == "" {
+		return c
+	}
+	c.final = seq[len(seq)-1]
+	seq = seq[:len(seq)-1]
+	if seq != "" && seq[0] >= '<' && seq[0] <= '?' {
+		c.prefix = seq[0]
+		seq = seq[1:]
+	}
+	if n := len(seq); n > 0 && seq[n-1] >= ' ' && seq[n-1] <= '/' {
+		c.intermediate = seq[n-1]
+		seq = seq[:n-1]
+	}
+	if seq != "" {
+		c.params = strings.Split(seq, ";")
+	}
+	return c
+}
+
+// param returns the i-th parameter, or def if it's missing or zero.
+func (c csi) param(i, def int) int {
+	if i >= len(c.params) {
+		return def
+	}
+	p, _, _ := strings.Cut(c.params[i], ":")
+	n, err := strconv.Atoi(p)
+	if err != nil || n == 0 {
+		return def
+	}
+	return n
+}
+
+// handleCSI handles a control sequence.
+func (t *Terminal) handleCSI(seq string) {
+	c := parseCSI(seq)
+
+	if c.prefix == '?' && c.intermediate == 0 {
+		switch c.final {
+		case 'h', 'l':
+			for i := range c.params {
+				t.setMode(c.param(i, 0), c.final == 'h')
+			}
+		}
+		return
+	}
+	if c.prefix != 0 || c.intermediate != 0 {
+		// Kitty keyboard, cursor style, and other sequences that don't
+		// affect the screen.
+		return
+	}
+
+	switch c.final {
+	case 'A': // CUU
+		t.cursorUp(c.param(0, 1))
+	case 'B': // CUD
+		t.cursorDown(c.param(0, 1))
+	case 'C': // CUF
+		t.cur.x = min(t.cur.x+c.param(0, 1), t.width-1)
+	case 'D': // CUB
+		t.cur.x = max(t.cur.x-c.param(0, 1), 0)
+	case 'E': // CNL
+		t.cursorDown(c.param(0, 1))
+		t.cur.x = 0
+	case 'F': // CPL
+		t.cursorUp(c.param(0, 1))
+		t.cur.x = 0
+	case 'G', '`': // CHA, HPA
+		t.cur.x = c.param(0, 1) - 1
+	case 'H', 'f': // CUP, HVP
+		t.cur.y = c.param(0, 1) - 1
+		t.cur.x = c.param(1, 1) - 1
+	case 'd': // VPA
+		t.cur.y = c.param(0, 1) - 1
+	case 'J': // ED
+		t.eraseDisplay(c.param(0, 0))
+	case 'K': // EL
+		switch c.param(0, 0) {
+		case 0:
+			t.erase(t.cur.y, t.cur.x, t.width)
+		case 1:
+			t.erase(t.cur.y, 0, t.cur.x+1)
+		case 2: //nolint:mnd
+			t.erase(t.cur.y, 0, t.width)
+		}
+	case 'L': // IL
+		t.insertLines(t.cur.y, c.param(0, 1))
+		t.cur.x = 0
+	case 'M': // DL
+		t.deleteLines(t.cur.y, c.param(0, 1), false)
+		t.cur.x = 0
+	case '@': // ICH
+		t.insertChars(c.param(0, 1))
+	case 'P': // DCH
+		t.deleteChars(c.param(0, 1))
+	case 'X': // ECH
+		t.erase(t.cur.y, t.cur.x, t.cur.x+c.param(0, 1))
+	case 'S': // SU
+		t.scrollUp(c.param(0, 1))
+	case 'T': // SD
+		t.scrollDown(c.param(0, 1))
+	case 'r': // DECSTBM
+		top, bottom := c.param(0, 1)-1, c.param(1, t.height)-1
+		if bottom >= t.height {
+			bottom = t.height - 1
+		}
+		if top < bottom {
+			t.top, t.bottom = top, bottom
+			t.cur.x, t.cur.y = 0, 0
+		}
+	case 'm': // SGR
+		t.cur.pen.Apply(c.params)
+	case 's': // SCOSC
+		t.saved = t.cur
+	case 'u': // SCORC
+		t.cur = t.saved
+	}
+
+	t.cur.wrapPending = false
+	t.clampCursor()
+}
+
+// cursorUp moves the cursor up, stopping at the top margin if the cursor is
+// in the scroll region.
+func (t *Terminal) cursorUp(n int) {
+	limit := 0
+	if t.cur.y >= t.top {
+		limit = t.top
+	}
+	t.cur.y = max(t.cur.y-n, limit)
+}
+
+// cursorDown moves the cursor down, stopping at the bottom margin if the
+// cursor is in the scroll region.
+func (t *Terminal) cursorDown(n int) {
+	limit := t.height - 1
+	if t.cur.y <= t.bottom {
+		limit = t.bottom
+	}
+	t.cur.y = min(t.cur.y+n, limit)
+}
+
+// eraseDisplay handles ED.
+func (t *Terminal) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		t.erase(t.cur.y, t.cur.x, t.width)
+		for y := t.cur.y + 1; y < t.height; y++ {
+			t.erase(y, 0, t.width)
+		}
+	case 1:
+		for y := 0; y < t.cur.y; y++ {
+			t.erase(y, 0, t.width)
+		}
+		t.erase(t.cur.y, 0, t.cur.x+1)
+	case 2: //nolint:mnd
+		for y := 0; y < t.height; y++ {
+			t.erase(y, 0, t.width)
+		}
+	case 3: //nolint:mnd
+		t.scrollback = nil
+	}
+}
+
+// insertChars inserts n blank cells at the cursor, shifting the rest of the
+// line right.
+func (t *Terminal) insertChars(n int) {
+	line := t.scr.lines[t.cur.y]
+	n = min(n, t.width-t.cur.x)
+	t.clearWide(line, t.cur.x, 0)
+	copy(line[t.cur.x+n:], line[t.cur.x:t.width-n])
+	for x := t.cur.x; x < t.cur.x+n; x++ {
+		line[x] = blankCell(Style{Bg: t.cur.pen.Bg})
+	}
+	// A wide character pushed over the edge.
+	if last := line[t.width-1]; last.Width > 1 {
+		line[t.width-1] = blankCell(last.Style)
+	}
+}
+
+// deleteChars deletes n cells at the cursor, shifting the rest of the line
+// left.
+func (t *Terminal) deleteChars(n int) {
+	line := t.scr.lines[t.cur.y]
+	n = min(n, t.width-t.cur.x)
+	t.clearWide(line, t.cur.x, n)
+	copy(line[t.cur.x:], line[t.cur.x+n:])
+	for x := t.width - n; x < t.width; x++ {
+		line[x] = blankCell(Style{Bg: t.cur.pen.Bg})
+	}
+}
@@ -0,0 +1,575 @@
+// Package vt implements a virtual terminal emulator. It interprets the escape
+// sequences Bubble Tea writes, such as cursor movements, erasing, scroll
+// regions, the alternate screen and SGR styles, into a grid of cells that can
+// be inspected, e.g. to test what a program actually shows on screen.
+//
+// It implements the subset of xterm that Bubble Tea and most TUIs use; other
+// sequences are ignored.
+package vt
+
+import (
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// DefaultScrollbackSize is the number of lines kept in the scrollback buffer
+// unless set with [Terminal.SetScrollbackSize].
+const DefaultScrollbackSize = 1000
+
+// tabWidth is the distance between tab stops.
+const tabWidth = 8
+
+// DEC private modes the terminal acts on.
+const (
+	modeAutoWrap       = 7
+	modeShowCursor     = 25
+	modeAltScreen      = 47
+	modeAltScreenClear = 1047
+	modeSaveCursor     = 1048
+	modeAltScreenSave  = 1049
+)
+
+// cursor is the cursor position along with the state saved with it by
+// DECSC.
+type cursor struct {
+	x, y int
+	pen  Style
+	link string
+
+	// wrapPending is set when a character was written in the last column:
+	// the cursor stays there until the next character is written, which
+	// wraps to the next line.
+	wrapPending bool
+}
+
+// screen is a screen buffer.
+type screen struct {
+	lines []Line
+}
+
+func newScreen(width, height int) *screen {
+	s := &screen{lines: make([]Line, height)}
+	for i := range s.lines {
+		s.lines[i] = newLine(width, Style{})
+	}
+	return s
+}
+
+// Terminal is a virtual terminal. Write the output of a program to it and
+// inspect its screen. It's safe for concurrent use.
+type Terminal struct {
+	mtx sync.Mutex
+
+	width, height int
+
+	main, alt *screen
+	scr       *screen // the active screen
+
+	cur   cursor
+	saved cursor
+
+	// scroll region, inclusive
+	top, bottom int
+
+	scrollback     []Line
+	scrollbackSize int
+
+	modes map[int]bool
+	title string
+
+	// pending holds an incomplete sequence or character from the previous
+	// write.
+	pending []byte
+}
+
+// New returns a terminal of the given size.
+func New(width, height int) *Terminal {
+	t := &Terminal{
+		width:          width,
+		height:         height,
+		main:           newScreen(width, height),
+		alt:            newScreen(width, height),
+		bottom:         height - 1,
+		scrollbackSize: DefaultScrollbackSize,
+		modes: map[int]bool{
+			modeAutoWrap:   true,
+			modeShowCursor: true,
+		},
+	}
+	t.scr = t.main
+	return t
+}
+
+// Write interprets the given output, updating the screen. It implements
+// [io.Writer]. Sequences split across writes are handled.
+func (t *Terminal) Write(p []byte) (int, error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	b := p
+	if len(t.pending) > 0 {
+		b = append(t.pending, p...)
+		t.pending = nil
+	}
+
+	for len(b) > 0 {
+		if b[0] >= utf8.RuneSelf && !utf8.FullRune(b) {
+			// The character continues in the next write.
+			t.pending = append([]byte(nil), b...)
+			break
+		}
+		seq, width, n, state := ansi.DecodeSequence(b, ansi.NormalState, nil)
+		if state != ansi.NormalState && n == len(b) {
+			// The sequence continues in the next write.
+			t.pending = append([]byte(nil), b...)
+			break
+		}
+		b = b[n:]
+		t.handle(seq, width)
+	}
+	return len(p), nil
+}
+
+// WriteString is like Write but takes a string.
+func (t *Terminal) WriteString(s string) (int, error) {
+	return t.Write([]byte(s))
+}
+
+// Width returns the width of the terminal.
+func (t *Terminal) Width() int {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return t.width
+}
+
+// Height returns the height of the terminal.
+func (t *Terminal) Height() int {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return t.height
+}
+
+// Cell returns the cell at the given position of the active screen. It
+// returns a zero cell if the position is outside of the screen.
+func (t *Terminal) Cell(x, y int) Cell {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	if x < 0 || y < 0 || x >= t.width || y >= t.height {
+		return Cell{}
+	}
+	return t.scr.lines[y][x]
+}
+
+// Line returns a copy of the given line of the active screen.
+func (t *Terminal) Line(y int) Line {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	if y < 0 || y >= t.height {
+		return nil
+	}
+	return append(Line(nil), t.scr.lines[y]...)
+}
+
+// String returns the text of the active screen, without styles, one line per
+// row. Trailing spaces are removed from each line.
+func (t *Terminal) String() string {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	lines := make([]string, len(t.scr.lines))
+	for i, l := range t.scr.lines {
+		lines[i] = l.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Cursor returns the cursor position, zero-based.
+func (t *Terminal) Cursor() (x, y int) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return t.cur.x, t.cur.y
+}
+
+// CursorVisible reports whether the cursor is shown.
+func (t *Terminal) CursorVisible() bool {
+	return t.Mode(modeShowCursor)
+}
+
+// AltScreen reports whether the alternate screen is active.
+func (t *Terminal) AltScreen() bool {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return t.scr == t.alt
+}
+
+// Mode reports whether the given DEC private mode is set, e.g. 2004 for
+// bracketed paste.
+func (t *Terminal) Mode(mode int) bool {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return t.modes[mode]
+}
+
+// Title returns the window title set by the program.
+func (t *Terminal) Title() string {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return t.title
+}
+
+// Scrollback returns a copy of the lines that scrolled off the top of the
+// main screen, oldest first.
+func (t *Terminal) Scrollback() []Line {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	lines := make([]Line, len(t.scrollback))
+	for i, l := range t.scrollback {
+		lines[i] = append(Line(nil), l...)
+	}
+	return lines
+}
+
+// SetScrollbackSize sets the maximum number of lines kept in the scrollback
+// buffer. Zero disables the scrollback buffer.
+func (t *Terminal) SetScrollbackSize(n int) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.scrollbackSize = n
+	t.trimScrollback()
+}
+
+// Resize changes the size of the terminal. Lines are cut or padded to the
+// new width. When the main screen gets shorter, the lines above the cursor
+// move to the scrollback buffer so the cursor stays on screen.
+func (t *Terminal) Resize(width, height int) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	for _, s := range []*screen{t.main, t.alt} {
+		for i, l := range s.lines {
+			s.lines[i] = resizeLine(l, width)
+		}
+		if height < len(s.lines) {
+			drop := 0
+			if s == t.scr && t.cur.y >= height {
+				drop = t.cur.y - height + 1
+			}
+			if s == t.main && t.scrollbackSize > 0 {
+				t.scrollback = append(t.scrollback, s.lines[:drop]...)
+			}
+			s.lines = s.lines[drop : drop+height]
+			if s == t.scr {
+				t.cur.y -= drop
+			}
+		}
+		for len(s.lines) < height {
+			s.lines = append(s.lines, newLine(width, Style{}))
+		}
+	}
+	t.trimScrollback()
+
+	t.width, t.height = width, height
+	t.top, t.bottom = 0, height-1
+	t.cur.wrapPending = false
+	t.clampCursor()
+}
+
+// resizeLine cuts or pads a line to the given width.
+func resizeLine(l Line, width int) Line {
+	if len(l) >= width {
+		l = l[:width]
+		// Don't leave half of a wide character behind.
+		if width > 0 && l[width-1].Width > 1 {
+			l[width-1] = blankCell(l[width-1].Style)
+		}
+		return l
+	}
+	for len(l) < width {
+		l = append(l, blankCell(Style{}))
+	}
+	return l
+}
+
+// handle handles a decoded sequence or character.
+func (t *Terminal) handle(seq []byte, width int) {
+	if width > 0 {
+		t.print(string(seq), width)
+		return
+	}
+	if len(seq) == 0 {
+		return
+	}
+
+	switch c := seq[0]; {
+	case c == ansi.ESC && len(seq) > 1:
+		switch seq[1] {
+		case '[':
+			t.handleCSI(string(seq))
+		case ']':
+			t.handleOSC(string(seq))
+		default:
+			t.handleESC(string(seq[1:]))
+		}
+	case c == ansi.CSI:
+		t.handleCSI("\x1b[" + string(seq[1:]))
+	case c == ansi.OSC:
+		t.handleOSC("\x1b]" + string(seq[1:]))
+	case c < ' ' || c == ansi.DEL:
+		t.handleControl(c)
+	}
+}
+
+// handleControl handles a C0 control character.
+func (t *Terminal) handleControl(c byte) {
+	switch c {
+	case ansi.BS:
+		if t.cur.x > 0 {
+			t.cur.x--
+		}
+		t.cur.wrapPending = false
+	case ansi.HT:
+		x := (t.cur.x/tabWidth + 1) * tabWidth
+		if x >= t.width {
+			x = t.width - 1
+		}
+		t.cur.x = x
+		t.cur.wrapPending = false
+	case ansi.LF, ansi.VT, ansi.FF:
+		t.lineFeed()
+	case ansi.CR:
+		t.cur.x = 0
+		t.cur.wrapPending = false
+	}
+}
+
+// handleESC handles an escape sequence, given without the leading ESC.
+func (t *Terminal) handleESC(seq string) {
+	switch seq {
+	case "7":
+		t.saved = t.cur
+	case "8":
+		t.cur = t.saved
+		t.clampCursor()
+	case "D":
+		t.lineFeed()
+	case "E":
+		t.cur.x = 0
+		t.lineFeed()
+	case "M":
+		t.reverseIndex()
+	case "c":
+		nt := New(t.width, t.height)
+		nt.scrollbackSize = t.scrollbackSize
+		t.adopt(nt)
+	}
+}
+
+// adopt takes over the state of another terminal, keeping the mutex.
+func (t *Terminal) adopt(o *Terminal) {
+	t.width, t.height = o.width, o.height
+	t.main, t.alt, t.scr = o.main, o.alt, o.main
+	t.cur, t.saved = o.cur, o.saved
+	t.top, t.bottom = o.top, o.bottom
+	t.scrollback, t.scrollbackSize = o.scrollback, o.scrollbackSize
+	t.modes, t.title = o.modes, o.title
+}
+
+// handleOSC handles an operating system command.
+func (t *Terminal) handleOSC(seq string) {
+	seq = strings.TrimPrefix(seq, "\x1b]")
+	seq = strings.TrimSuffix(seq, "\x07")
+	seq = strings.TrimSuffix(seq, "\x1b\\")
+	cmd, data, _ := strings.Cut(seq, ";")
+	switch cmd {
+	case "0", "2":
+		t.title = data
+	case "8":
+		// OSC 8 ; params ; uri
+		_, uri, _ := strings.Cut(data, ";")
+		t.cur.link = uri
+	}
+}
+
+// print draws a character at the cursor position and advances the cursor.
+func (t *Terminal) print(content string, width int) {
+	if t.width <= 0 || t.height <= 0 {
+		return
+	}
+	if width > t.width {
+		return
+	}
+
+	if t.cur.wrapPending || t.cur.x+width > t.width {
+		if t.modes[modeAutoWrap] {
+			t.cur.x = 0
+			t.lineFeed()
+		} else {
+			t.cur.x = t.width - width
+		}
+	}
+	t.cur.wrapPending = false
+
+	line := t.scr.lines[t.cur.y]
+	t.clearWide(line, t.cur.x, width)
+	line[t.cur.x] = Cell{Content: content, Width: width, Style: t.cur.pen, Link: t.cur.link}
+	for i := 1; i < width; i++ {
+		line[t.cur.x+i] = Cell{Style: t.cur.pen, Link: t.cur.link}
+	}
+
+	t.cur.x += width
+	if t.cur.x >= t.width {
+		t.cur.x = t.width - 1
+		t.cur.wrapPending = true
+	}
+}
+
+// clearWide blanks the parts of wide characters that are partially covered
+// by drawing width cells at x.
+func (t *Terminal) clearWide(line Line, x, width int) {
+	// A wide character to the left whose right-hand side gets overwritten.
+	if line[x].IsContinuation() {
+		for i := x - 1; i >= 0; i-- {
+			cont := line[i].IsContinuation()
+			line[i] = blankCell(line[i].Style)
+			if !cont {
+				break
+			}
+		}
+	}
+	// A wide character whose left-hand side gets overwritten.
+	for i := x + width; i < len(line) && line[i].IsContinuation(); i++ {
+		line[i] = blankCell(line[i].Style)
+	}
+}
+
+// lineFeed moves the cursor down, scrolling the scroll region when the
+// cursor is on its last line.
+func (t *Terminal) lineFeed() {
+	t.cur.wrapPending = false
+	switch {
+	case t.cur.y == t.bottom:
+		t.scrollUp(1)
+	case t.cur.y < t.height-1:
+		t.cur.y++
+	}
+}
+
+// reverseIndex moves the cursor up, scrolling the scroll region down when
+// the cursor is on its first line.
+func (t *Terminal) reverseIndex() {
+	t.cur.wrapPending = false
+	switch {
+	case t.cur.y == t.top:
+		t.scrollDown(1)
+	case t.cur.y > 0:
+		t.cur.y--
+	}
+}
+
+// scrollUp scrolls the scroll region up by n lines. Lines scrolled off the
+// top of the main screen go to the scrollback buffer.
+func (t *Terminal) scrollUp(n int) {
+	t.deleteLines(t.top, n, t.top == 0 && t.scr == t.main)
+}
+
+// scrollDown scrolls the scroll region down by n lines.
+func (t *Terminal) scrollDown(n int) {
+	t.insertLines(t.top, n)
+}
+
+// insertLines inserts n blank lines at y, pushing the lines below down
+// within the scroll region.
+func (t *Terminal) insertLines(y, n int) {
+	if y < t.top || y > t.bottom {
+		return
+	}
+	n = min(n, t.bottom-y+1)
+	lines := t.scr.lines
+	copy(lines[y+n:t.bottom+1], lines[y:t.bottom+1-n])
+	for i := y; i < y+n; i++ {
+		lines[i] = newLine(t.width, Style{Bg: t.cur.pen.Bg})
+	}
+}
+
+// deleteLines deletes n lines at y, pulling the lines below up within the
+// scroll region, optionally keeping the deleted lines in the scrollback
+// buffer.
+func (t *Terminal) deleteLines(y, n int, keep bool) {
+	if y < t.top || y > t.bottom {
+		return
+	}
+	n = min(n, t.bottom-y+1)
+	lines := t.scr.lines
+	if keep && t.scrollbackSize > 0 {
+		t.scrollback = append(t.scrollback, lines[y:y+n]...)
+		t.trimScrollback()
+	}
+	copy(lines[y:t.bottom+1-n], lines[y+n:t.bottom+1])
+	for i := t.bottom + 1 - n; i <= t.bottom; i++ {
+		lines[i] = newLine(t.width, Style{Bg: t.cur.pen.Bg})
+	}
+}
+
+func (t *Terminal) trimScrollback() {
+	if over := len(t.scrollback) - t.scrollbackSize; over > 0 {
+		t.scrollback = append([]Line(nil), t.scrollback[over:]...)
+	}
+}
+
+// erase blanks the cells in [from, to) of the given line.
+func (t *Terminal) erase(y, from, to int) {
+	line := t.scr.lines[y]
+	from, to = max(from, 0), min(to, len(line))
+	if from >= to {
+		return
+	}
+	t.clearWide(line, from, to-from)
+	for x := from; x < to; x++ {
+		line[x] = blankCell(Style{Bg: t.cur.pen.Bg})
+	}
+}
+
+// clampCursor keeps the cursor on screen.
+func (t *Terminal) clampCursor() {
+	t.cur.x = max(0, min(t.cur.x, t.width-1))
+	t.cur.y = max(0, min(t.cur.y, t.height-1))
+}
+
+// setMode sets or resets a DEC private mode.
+func (t *Terminal) setMode(mode int, set bool) {
+	switch mode {
+	case modeAltScreen, modeAltScreenClear, modeAltScreenSave:
+		if set == (t.scr == t.alt) {
+			break
+		}
+		if set {
+			if mode == modeAltScreenSave {
+				t.saved = t.cur
+			}
+			t.scr = t.alt
+			if mode != modeAltScreen {
+				t.alt = newScreen(t.width, t.height)
+				t.scr = t.alt
+			}
+		} else {
+			if mode == modeAltScreenClear {
+				t.alt = newScreen(t.width, t.height)
+			}
+			t.scr = t.main
+			if mode == modeAltScreenSave {
+				t.cur = t.saved
+				t.clampCursor()
+			}
+		}
+	case modeSaveCursor:
+		if set {
+			t.saved = t.cur
+		} else {
+			t.cur = t.saved
+			t.clampCursor()
+		}
+	}
+	t.modes[mode] = set
+}
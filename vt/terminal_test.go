@@ -0,0 +1,256 @@
+package vt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestTerminal(t *testing.T) {
+	tests := []struct {
+		name     string
+		width    int
+		height   int
+		input    string
+		expected string
+		x, y     int
+	}{
+		{
+			name:     "text",
+			width:    10,
+			height:   3,
+			input:    "hello\r\nworld",
+			expected: "hello\nworld\n",
+			x:        5,
+			y:        1,
+		},
+		{
+			name:     "line feed keeps the column",
+			width:    10,
+			height:   3,
+			input:    "ab\ncd",
+			expected: "ab\n  cd\n",
+			x:        4,
+			y:        1,
+		},
+		{
+			name:     "wrap",
+			width:    4,
+			height:   3,
+			input:    "abcdef",
+			expected: "abcd\nef\n",
+			x:        2,
+			y:        1,
+		},
+		{
+			name:     "pending wrap",
+			width:    4,
+			height:   3,
+			input:    "abcd\r\nef",
+			expected: "abcd\nef\n",
+			x:        2,
+			y:        1,
+		},
+		{
+			name:     "scroll",
+			width:    4,
+			height:   2,
+			input:    "a\r\nb\r\nc",
+			expected: "b\nc",
+			x:        1,
+			y:        1,
+		},
+		{
+			name:     "cursor movement",
+			width:    10,
+			height:   3,
+			input:    "abc\r\ndef" + ansi.CursorUp(1) + ansi.CursorForward(2) + "X" + ansi.CursorPosition(2, 3) + "Y",
+			expected: "abc  X\ndef\n Y",
+			x:        2,
+			y:        2,
+		},
+		{
+			name:     "erase line",
+			width:    10,
+			height:   2,
+			input:    "hello wor\r" + ansi.CursorForward(2) + ansi.EraseLineRight,
+			expected: "he\n",
+			x:        2,
+			y:        0,
+		},
+		{
+			name:     "erase display",
+			width:    10,
+			height:   3,
+			input:    "one\r\ntwo\r\nthree" + ansi.CursorUp(1) + "\r" + ansi.EraseScreenBelow,
+			expected: "one\n\n",
+			x:        0,
+			y:        1,
+		},
+		{
+			name:     "wide characters",
+			width:    5,
+			height:   2,
+			input:    "a世b\r" + ansi.CursorForward(2) + "x",
+			expected: "a xb\n",
+			x:        3,
+			y:        0,
+		},
+		{
+			name:     "insert line in scroll region",
+			width:    5,
+			height:   4,
+			input:    "1\r\n2\r\n3\r\n4" + ansi.SetTopBottomMargins(2, 3) + ansi.CursorPosition(1, 2) + ansi.InsertLine(1) + "x",
+			expected: "1\nx\n2\n4",
+			x:        1,
+			y:        1,
+		},
+		{
+			name:     "scroll region",
+			width:    5,
+			height:   4,
+			input:    "1\r\n2\r\n3\r\n4" + ansi.SetTopBottomMargins(2, 3) + ansi.CursorPosition(1, 3) + "\r\nx",
+			expected: "1\n3\nx\n4",
+			x:        1,
+			y:        2,
+		},
+		{
+			name:     "split sequence",
+			width:    5,
+			height:   1,
+			input:    "ab\x1b",
+			expected: "ab",
+			x:        2,
+			y:        0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			term := New(test.width, test.height)
+			if _, err := term.WriteString(test.input); err != nil {
+				t.Fatal(err)
+			}
+			if got := term.String(); got != test.expected {
+				t.Errorf("expected screen:\n%q\ngot:\n%q", test.expected, got)
+			}
+			if x, y := term.Cursor(); x != test.x || y != test.y {
+				t.Errorf("expected cursor at %d,%d, got %d,%d", test.x, test.y, x, y)
+			}
+		})
+	}
+}
+
+func TestTerminalSplitWrites(t *testing.T) {
+	term := New(10, 1)
+	input := "\x1b[1;31m世界\x1b]0;title\x07"
+	for i := 0; i < len(input); i++ {
+		if _, err := term.Write([]byte{input[i]}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := term.String(); got != "世界" {
+		t.Errorf("expected %q, got %q", "世界", got)
+	}
+	if c := term.Cell(2, 0); c.Content != "界" || !c.Style.Bold || c.Style.Fg != ansi.BasicColor(1) {
+		t.Errorf("unexpected cell %#v", c)
+	}
+	if term.Title() != "title" {
+		t.Errorf("expected title %q, got %q", "title", term.Title())
+	}
+}
+
+func TestTerminalStyles(t *testing.T) {
+	term := New(10, 1)
+	term.WriteString("\x1b[1;4:3;38;5;208;48;2;1;2;3ma\x1b[22;39mb\x1b[mc") //nolint:errcheck
+	a, b, c := term.Cell(0, 0), term.Cell(1, 0), term.Cell(2, 0)
+
+	expected := Style{
+		Fg:        ansi.ExtendedColor(208),
+		Bg:        ansi.TrueColor(0x010203),
+		Underline: ansi.CurlyUnderlineStyle,
+		Bold:      true,
+	}
+	if a.Style != expected {
+		t.Errorf("expected style %#v, got %#v", expected, a.Style)
+	}
+	expected.Bold, expected.Fg = false, nil
+	if b.Style != expected {
+		t.Errorf("expected style %#v, got %#v", expected, b.Style)
+	}
+	if !c.Style.IsZero() {
+		t.Errorf("expected default style, got %#v", c.Style)
+	}
+}
+
+func TestTerminalHyperlink(t *testing.T) {
+	term := New(10, 1)
+	term.WriteString(ansi.SetHyperlink("https://example.com") + "a" + ansi.ResetHyperlink() + "b") //nolint:errcheck
+	if l := term.Cell(0, 0).Link; l != "https://example.com" {
+		t.Errorf("expected link, got %q", l)
+	}
+	if l := term.Cell(1, 0).Link; l != "" {
+		t.Errorf("expected no link, got %q", l)
+	}
+}
+
+func TestTerminalAltScreen(t *testing.T) {
+	term := New(10, 2)
+	term.WriteString("main")                                                    //nolint:errcheck
+	term.WriteString(ansi.SetAltScreenSaveCursorMode + ansi.HideCursor + "alt") //nolint:errcheck
+	if !term.AltScreen() || term.CursorVisible() {
+		t.Error("expected the alt screen to be active and the cursor hidden")
+	}
+	if got := term.String(); got != "    alt\n" {
+		t.Errorf("expected alt screen %q, got %q", "    alt\n", got)
+	}
+
+	term.WriteString(ansi.ResetAltScreenSaveCursorMode + ansi.ShowCursor) //nolint:errcheck
+	if term.AltScreen() || !term.CursorVisible() {
+		t.Error("expected the main screen to be active and the cursor shown")
+	}
+	if got := term.String(); got != "main\n" {
+		t.Errorf("expected main screen %q, got %q", "main\n", got)
+	}
+	if x, y := term.Cursor(); x != 4 || y != 0 {
+		t.Errorf("expected the cursor to be restored to 4,0, got %d,%d", x, y)
+	}
+}
+
+func TestTerminalScrollback(t *testing.T) {
+	term := New(5, 2)
+	term.SetScrollbackSize(2)
+	term.WriteString("1\r\n2\r\n3\r\n4\r\n5") //nolint:errcheck
+
+	var lines []string
+	for _, l := range term.Scrollback() {
+		lines = append(lines, l.String())
+	}
+	if got := strings.Join(lines, ","); got != "2,3" {
+		t.Errorf("expected scrollback %q, got %q", "2,3", got)
+	}
+
+	// Scrolling the alt screen or a scroll region that doesn't start at the
+	// top doesn't touch the scrollback.
+	term.WriteString(ansi.SetTopBottomMargins(2, 2) + ansi.CursorPosition(1, 2) + "\n") //nolint:errcheck
+	term.WriteString(ansi.SetAltScreenSaveCursorMode + "a\r\nb\r\nc")                   //nolint:errcheck
+	if n := len(term.Scrollback()); n != 2 {
+		t.Errorf("expected 2 lines of scrollback, got %d", n)
+	}
+}
+
+func TestTerminalResize(t *testing.T) {
+	term := New(5, 3)
+	term.WriteString("1\r\n2\r\n3") //nolint:errcheck
+	term.Resize(3, 2)
+	if got := term.String(); got != "2\n3" {
+		t.Errorf("expected screen %q, got %q", "2\n3", got)
+	}
+	if x, y := term.Cursor(); x != 1 || y != 1 {
+		t.Errorf("expected cursor at 1,1, got %d,%d", x, y)
+	}
+	if n := len(term.Scrollback()); n != 1 {
+		t.Errorf("expected 1 line of scrollback, got %d", n)
+	}
+}